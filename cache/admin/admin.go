@@ -0,0 +1,173 @@
+package admin
+
+import (
+	"cache/lru"
+	"fmt"
+	"gee"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Admin 保存按名字注册的 lru.Cache，并提供查看统计、查找、删除和清空记录的 HTTP 接口
+// 运维人员可以通过这些接口检查运行中的缓存，而不需要重新部署
+// admin 是单独的模块，只有使用管理接口的程序才依赖 gee，cache 模块本身保持没有外部依赖
+type Admin struct {
+	mu     sync.RWMutex
+	caches map[string]*registered
+}
+
+// registered 是一个已注册的缓存，lru.Cache 本身不是并发安全的，所有访问都需要持有 mu
+type registered struct {
+	mu    sync.Locker
+	cache *lru.Cache
+}
+
+// New 创建一个空的 Admin
+func New() *Admin {
+	return &Admin{caches: make(map[string]*registered)}
+}
+
+// Register 以 name 注册一个缓存，mu 是业务代码访问该缓存时使用的锁，为 nil 表示缓存只在 Admin 中使用
+// 同名的缓存会被覆盖
+func (a *Admin) Register(name string, cache *lru.Cache, mu sync.Locker) {
+	if mu == nil {
+		mu = &sync.Mutex{}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.caches[name] = &registered{mu: mu, cache: cache}
+}
+
+// Unregister 取消注册指定名字的缓存
+func (a *Admin) Unregister(name string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.caches, name)
+}
+
+// Mount 将管理接口挂载到路由分组上，例如 admin.Mount(r.Group("/admin"))
+//
+//	GET  /caches                    列出所有缓存的名字
+//	GET  /caches/:name/stats        查看统计信息
+//	GET  /caches/:name/keys/*key    查找记录，与 Get 相同会计入统计并标记为最近使用
+//	GET  /caches/:name/peek/*key    查看记录，不改变记录的新旧顺序
+//	POST /caches/:name/delete/*key  删除记录
+//	POST /caches/:name/flush        清空缓存
+func (a *Admin) Mount(group *gee.RouteGroup) {
+	group.GET("/caches", a.list)
+	group.GET("/caches/:name/stats", a.stats)
+	group.GET("/caches/:name/keys/*key", a.lookup)
+	group.GET("/caches/:name/peek/*key", a.peek)
+	group.POST("/caches/:name/delete/*key", a.delete)
+	group.POST("/caches/:name/flush", a.flush)
+}
+
+// get 返回请求路径中 name 对应的缓存，不存在时返回 404
+func (a *Admin) get(c *gee.Context) (*registered, bool) {
+	a.mu.RLock()
+	r, ok := a.caches[c.Param("name")]
+	a.mu.RUnlock()
+	if !ok {
+		c.Fail(http.StatusNotFound, "cache not found: "+c.Param("name"))
+	}
+	return r, ok
+}
+
+func (a *Admin) list(c *gee.Context) {
+	a.mu.RLock()
+	names := make([]string, 0, len(a.caches))
+	for name := range a.caches {
+		names = append(names, name)
+	}
+	a.mu.RUnlock()
+	sort.Strings(names)
+	c.JSON(http.StatusOK, gee.H{"caches": names})
+}
+
+func (a *Admin) stats(c *gee.Context) {
+	r, ok := a.get(c)
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	s := r.cache.Stats()
+	maxBytes, maxEntries := r.cache.MaxBytes(), r.cache.MaxEntries()
+	r.mu.Unlock()
+
+	// EvictionReason 没有实现 encoding.TextMarshaler，转成字符串作为 JSON 的键
+	evictions := make(map[string]uint64, len(s.Evictions))
+	for reason, n := range s.Evictions {
+		evictions[reason.String()] = n
+	}
+	c.JSON(http.StatusOK, gee.H{
+		"name":       c.Param("name"),
+		"hits":       s.Hits,
+		"misses":     s.Misses,
+		"hitRatio":   s.HitRatio(),
+		"adds":       s.Adds,
+		"overwrites": s.Overwrites,
+		"evictions":  evictions,
+		"bytes":      s.Bytes,
+		"entries":    s.Entries,
+		"maxBytes":   maxBytes,
+		"maxEntries": maxEntries,
+	})
+}
+
+func (a *Admin) lookup(c *gee.Context) {
+	a.find(c, (*lru.Cache).Get)
+}
+
+func (a *Admin) peek(c *gee.Context) {
+	a.find(c, (*lru.Cache).Peek)
+}
+
+// find 使用 get 查找路径中的键，记录不存在时返回 404
+func (a *Admin) find(c *gee.Context, get func(*lru.Cache, string) (lru.Value, bool)) {
+	r, ok := a.get(c)
+	if !ok {
+		return
+	}
+	key := c.Param("key")
+	r.mu.Lock()
+	value, ok := get(r.cache, key)
+	r.mu.Unlock()
+	if !ok {
+		c.Fail(http.StatusNotFound, "key not found: "+key)
+		return
+	}
+	c.JSON(http.StatusOK, gee.H{
+		"key":   key,
+		"value": fmt.Sprint(value),
+		"bytes": value.Len(),
+	})
+}
+
+func (a *Admin) delete(c *gee.Context) {
+	r, ok := a.get(c)
+	if !ok {
+		return
+	}
+	key := c.Param("key")
+	r.mu.Lock()
+	removed := r.cache.Remove(key)
+	r.mu.Unlock()
+	if !removed {
+		c.Fail(http.StatusNotFound, "key not found: "+key)
+		return
+	}
+	c.JSON(http.StatusOK, gee.H{"key": key, "deleted": true})
+}
+
+func (a *Admin) flush(c *gee.Context) {
+	r, ok := a.get(c)
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	n := r.cache.Len()
+	r.cache.Clear(true)
+	r.mu.Unlock()
+	c.JSON(http.StatusOK, gee.H{"name": c.Param("name"), "flushed": n})
+}
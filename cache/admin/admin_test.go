@@ -0,0 +1,98 @@
+package admin
+
+import (
+	"cache/lru"
+	"encoding/json"
+	"gee"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type String string
+
+func (d String) Len() int {
+	return len(d)
+}
+
+// newTestServer 注册一个名为 scores 的缓存，并将管理接口挂载到 /admin 下
+func newTestServer() (*gee.Engine, *lru.Cache) {
+	c := lru.New(int64(0), nil)
+	c.Add("Tom", String("630"))
+	c.Add("a/b", String("589"))
+	a := New()
+	a.Register("scores", c, &sync.Mutex{})
+	r := gee.New()
+	a.Mount(r.Group("/admin"))
+	return r, c
+}
+
+// do 发送请求，检查状态码并解析返回的 JSON
+func do(t *testing.T, r http.Handler, method, path string, code int) map[string]interface{} {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	if w.Code != code {
+		t.Fatalf("%s %s: expect status %d, got %d: %s", method, path, code, w.Code, w.Body)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("%s %s: invalid json %q: %v", method, path, w.Body, err)
+	}
+	return body
+}
+
+func TestAdminLookup(t *testing.T) {
+	r, c := newTestServer()
+	if body := do(t, r, "GET", "/admin/caches/scores/keys/Tom", http.StatusOK); body["value"] != "630" || body["bytes"] != 3.0 {
+		t.Fatalf("unexpected body %v", body)
+	}
+	// 包含 / 的键由通配参数匹配
+	if body := do(t, r, "GET", "/admin/caches/scores/peek/a/b", http.StatusOK); body["value"] != "589" {
+		t.Fatalf("unexpected body %v", body)
+	}
+	do(t, r, "GET", "/admin/caches/scores/keys/unknown", http.StatusNotFound)
+	do(t, r, "GET", "/admin/caches/unknown/keys/Tom", http.StatusNotFound)
+
+	// peek 不计入统计
+	if s := c.Stats(); s.Hits != 1 || s.Misses != 1 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+	body := do(t, r, "GET", "/admin/caches/scores/stats", http.StatusOK)
+	if body["hits"] != 1.0 || body["entries"] != 2.0 || body["hitRatio"] != 0.5 {
+		t.Fatalf("unexpected stats %v", body)
+	}
+	if evictions, ok := body["evictions"].(map[string]interface{}); !ok || evictions["capacity"] != 0.0 {
+		t.Fatalf("unexpected evictions %v", body["evictions"])
+	}
+}
+
+func TestAdminDeleteAndFlush(t *testing.T) {
+	r, c := newTestServer()
+	do(t, r, "POST", "/admin/caches/scores/delete/Tom", http.StatusOK)
+	do(t, r, "POST", "/admin/caches/scores/delete/Tom", http.StatusNotFound)
+	if c.Contains("Tom") || c.Len() != 1 {
+		t.Fatalf("Tom should be deleted, len=%d", c.Len())
+	}
+	if body := do(t, r, "POST", "/admin/caches/scores/flush", http.StatusOK); body["flushed"] != 1.0 {
+		t.Fatalf("unexpected body %v", body)
+	}
+	if c.Len() != 0 {
+		t.Fatalf("cache should be empty after flush, len=%d", c.Len())
+	}
+}
+
+func TestAdminList(t *testing.T) {
+	a := New()
+	a.Register("b", lru.New(int64(0), nil), nil)
+	a.Register("a", lru.New(int64(0), nil), nil)
+	a.Register("c", lru.New(int64(0), nil), nil)
+	a.Unregister("c")
+	r := gee.New()
+	a.Mount(r.Group("/admin"))
+	body := do(t, r, "GET", "/admin/caches", http.StatusOK)
+	if names, ok := body["caches"].([]interface{}); !ok || len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Fatalf("unexpected caches %v", body["caches"])
+	}
+}
@@ -0,0 +1,13 @@
+module cache/admin
+
+go 1.22
+
+require (
+	cache v0.0.0
+	gee v0.0.0
+)
+
+replace (
+	cache => ../
+	gee => ../../web/gee
+)
@@ -0,0 +1,33 @@
+package cache
+
+// ByteView 是只读的字节视图，实现了 lru.Value 接口
+// 内部的 b 不会暴露给调用方，读取时总是返回拷贝，防止缓存的值被外部修改
+type ByteView struct {
+	b []byte
+}
+
+// NewByteView 拷贝 b 并创建 ByteView，之后修改 b 不会影响 ByteView
+func NewByteView(b []byte) ByteView {
+	return ByteView{b: cloneBytes(b)}
+}
+
+// Len 返回视图的长度，用于计算占用的内存
+func (v ByteView) Len() int {
+	return len(v.b)
+}
+
+// ByteSlice 返回数据的拷贝
+func (v ByteView) ByteSlice() []byte {
+	return cloneBytes(v.b)
+}
+
+// String 以字符串的形式返回数据，字符串本身是不可变的，因此同样不会泄露内部数据
+func (v ByteView) String() string {
+	return string(v.b)
+}
+
+func cloneBytes(b []byte) []byte {
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
@@ -0,0 +1,35 @@
+package cache
+
+import "testing"
+
+func TestByteView(t *testing.T) {
+	b := []byte("1234")
+	v := NewByteView(b)
+	b[0] = 'x'
+	if v.String() != "1234" {
+		t.Fatalf("ByteView should not share memory with the source slice, got %s", v.String())
+	}
+	out := v.ByteSlice()
+	out[0] = 'x'
+	if v.String() != "1234" || v.Len() != 4 {
+		t.Fatalf("ByteSlice should return a copy, got %s", v.String())
+	}
+}
+
+func TestCacheView(t *testing.T) {
+	c := New(int64(0), nil)
+	c.AddView("key1", NewByteView([]byte("1234")))
+	c.Add("key2", String("5678"))
+
+	v, ok := c.GetView("key1")
+	if !ok || v.String() != "1234" {
+		t.Fatalf("cache hit key1=1234 failed")
+	}
+	v.ByteSlice()[0] = 'x'
+	if v, _ := c.GetView("key1"); v.String() != "1234" {
+		t.Fatalf("cached value should not be mutated by callers, got %s", v.String())
+	}
+	if _, ok := c.GetView("key2"); ok {
+		t.Fatalf("non ByteView value key2 should not be returned")
+	}
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"cache/lru"
+	"sync"
+)
+
+// Cache 是淘汰策略的并发安全封装，所有操作都通过互斥锁串行化，默认使用 lru.Cache
+// 大多数淘汰策略的 Get 也会修改内部状态（例如 MoveToFront），因此这里使用 sync.Mutex 而不是读写锁
+type Cache struct {
+	mu     sync.Mutex
+	policy Policy
+}
+
+// New 是 Cache 的构造方法，参数含义与 lru.New 相同
+func New(maxBytes int64, onEvicted func(string, lru.Value)) *Cache {
+	return NewWithPolicy(lru.New(maxBytes, onEvicted))
+}
+
+// NewWithPolicy 使用指定的淘汰策略创建 Cache，例如 NewWithPolicy(lfu.New(maxBytes, onEvicted))
+// 创建之后不应再直接访问 policy，否则会绕过 Cache 的锁
+func NewWithPolicy(policy Policy) *Cache {
+	return &Cache{policy: policy}
+}
+
+// Get 查找键对应的值，并按淘汰策略记录这次访问
+func (c *Cache) Get(key string) (value lru.Value, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.policy.Get(key)
+}
+
+// Add 新增或更新一条记录，超出容量时按淘汰策略移除记录
+func (c *Cache) Add(key string, value lru.Value) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.policy.Add(key, value)
+}
+
+// batchPolicy 是支持批量读写的淘汰策略，例如 lru.Cache
+type batchPolicy interface {
+	AddMany(entries []lru.Entry) []error
+	GetMany(keys []string) ([]lru.Value, []bool)
+}
+
+// AddMany 在一次加锁中批量写入多条记录，返回的错误与 entries 一一对应
+// 淘汰策略支持批量写入时所有记录写入之后才统一淘汰，否则逐条调用 Add，错误均为 nil
+func (c *Cache) AddMany(entries []lru.Entry) []error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if b, ok := c.policy.(batchPolicy); ok {
+		return b.AddMany(entries)
+	}
+	for _, e := range entries {
+		c.policy.Add(e.Key, e.Value)
+	}
+	return make([]error, len(entries))
+}
+
+// GetMany 在一次加锁中批量查找多个键，返回的 values 和 ok 与 keys 一一对应
+func (c *Cache) GetMany(keys []string) (values []lru.Value, ok []bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if b, isBatch := c.policy.(batchPolicy); isBatch {
+		return b.GetMany(keys)
+	}
+	values = make([]lru.Value, len(keys))
+	ok = make([]bool, len(keys))
+	for i, key := range keys {
+		values[i], ok[i] = c.policy.Get(key)
+	}
+	return
+}
+
+// Remove 删除键对应的记录，返回记录是否存在
+func (c *Cache) Remove(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.policy.Remove(key)
+}
+
+// RemoveOldest 按淘汰策略移除一条记录，默认为最近最少使用的记录
+func (c *Cache) RemoveOldest() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.policy.RemoveOldest()
+}
+
+// Len 返回缓存中条目的数量
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.policy.Len()
+}
+
+// Bytes 返回缓存当前已使用的内存
+func (c *Cache) Bytes() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.policy.Bytes()
+}
+
+// Stats 返回淘汰策略的统计信息，例如 lru.Cache 的命中率，策略没有实现 Stats 方法时 ok 为 false
+func (c *Cache) Stats() (stats lru.Stats, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.policy.(interface{ Stats() lru.Stats }); ok {
+		return s.Stats(), true
+	}
+	return
+}
+
+// AddView 以 ByteView 的形式写入记录，是上层缓存保存数据的默认方式
+func (c *Cache) AddView(key string, value ByteView) {
+	c.Add(key, value)
+}
+
+// GetView 读取以 ByteView 形式保存的记录，记录不存在或不是 ByteView 时 ok 为 false
+func (c *Cache) GetView(key string) (value ByteView, ok bool) {
+	if v, hit := c.Get(key); hit {
+		value, ok = v.(ByteView)
+	}
+	return
+}
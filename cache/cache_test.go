@@ -0,0 +1,129 @@
+package cache
+
+import (
+	"cache/fifo"
+	"cache/lru"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+type String string
+
+func (d String) Len() int {
+	return len(d)
+}
+
+func TestCacheGet(t *testing.T) {
+	c := New(int64(0), nil)
+	c.Add("key1", String("1234"))
+	if v, ok := c.Get("key1"); !ok || string(v.(String)) != "1234" {
+		t.Fatalf("cache hit key1=1234 failed")
+	}
+	if _, ok := c.Get("key2"); ok {
+		t.Fatalf("cache miss key2 failed")
+	}
+}
+
+// TestCacheConcurrent 在多个 goroutine 中同时读写同一个 Cache，需配合 go test -race 运行
+func TestCacheConcurrent(t *testing.T) {
+	evicted := 0
+	var mu sync.Mutex
+	c := New(int64(1024), func(key string, value lru.Value) {
+		mu.Lock()
+		evicted++
+		mu.Unlock()
+	})
+
+	var wg sync.WaitGroup
+	for g := 0; g < 16; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				key := "key" + strconv.Itoa((g*1000+i)%200)
+				switch i % 4 {
+				case 0, 1:
+					c.Add(key, String(strconv.Itoa(i)))
+				case 2:
+					c.Get(key)
+				default:
+					if i%100 == 3 {
+						c.RemoveOldest()
+					}
+					c.Len()
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if c.Len() == 0 {
+		t.Fatalf("cache should not be empty after concurrent adds")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if evicted == 0 {
+		t.Fatalf("expect some entries to be evicted under a 1024 bytes limit")
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	c := New(int64(0), nil)
+	c.Add("key1", String("1234"))
+	c.Get("key1")
+	c.Get("key2")
+	s, ok := c.Stats()
+	if !ok || s.Hits != 1 || s.Misses != 1 || s.Entries != 1 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+	if _, ok := NewWithPolicy(fifo.New(int64(0), nil)).Stats(); ok {
+		t.Fatalf("fifo policy should not report stats")
+	}
+}
+
+func TestCacheBatch(t *testing.T) {
+	for _, c := range []*Cache{New(int64(0), nil), NewWithPolicy(fifo.New(int64(0), nil))} {
+		errs := c.AddMany([]lru.Entry{{Key: "key1", Value: String("1")}, {Key: "key2", Value: String("2")}})
+		if len(errs) != 2 || errs[0] != nil || errs[1] != nil {
+			t.Fatalf("unexpected errors %v", errs)
+		}
+		values, ok := c.GetMany([]string{"key2", "key3", "key1"})
+		if !ok[0] || ok[1] || !ok[2] || values[0] != String("2") || values[2] != String("1") {
+			t.Fatalf("unexpected results %v %v", values, ok)
+		}
+	}
+}
+
+// benchmarkBatch 比较循环调用 Add/Get 与一次 AddMany/GetMany 的开销，每次写入并读取 1000 个键
+func benchmarkBatch(b *testing.B, batch bool) {
+	entries := make([]lru.Entry, 1000)
+	keys := make([]string, len(entries))
+	for i := range entries {
+		keys[i] = "key" + strconv.Itoa(i)
+		entries[i] = lru.Entry{Key: keys[i], Value: String("value")}
+	}
+	c := New(int64(0), nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if batch {
+			c.AddMany(entries)
+			c.GetMany(keys)
+			continue
+		}
+		for _, e := range entries {
+			c.Add(e.Key, e.Value)
+		}
+		for _, k := range keys {
+			c.Get(k)
+		}
+	}
+}
+
+func BenchmarkCacheLoop(b *testing.B) {
+	benchmarkBatch(b, false)
+}
+
+func BenchmarkCacheBatch(b *testing.B) {
+	benchmarkBatch(b, true)
+}
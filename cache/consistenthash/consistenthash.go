@@ -0,0 +1,84 @@
+package consistenthash
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+)
+
+// Hash 将数据映射为 uint32，用于计算键和节点在哈希环上的位置
+type Hash func(data []byte) uint32
+
+// Map 是一致性哈希环，包含所有节点及其虚拟节点
+type Map struct {
+	hash     Hash           // 哈希函数
+	replicas int            // 每个真实节点对应的虚拟节点个数
+	keys     []int          // 已排序的哈希环
+	hashMap  map[int]string // 虚拟节点与真实节点的映射表，键是虚拟节点的哈希值，值是真实节点的名称
+}
+
+// New 创建一致性哈希环，replicas 是虚拟节点倍数，fn 为 nil 时默认使用 crc32.ChecksumIEEE
+func New(replicas int, fn Hash) *Map {
+	m := &Map{
+		replicas: replicas,
+		hash:     fn,
+		hashMap:  make(map[int]string),
+	}
+	if m.hash == nil {
+		m.hash = crc32.ChecksumIEEE
+	}
+	return m
+}
+
+// Add 添加真实节点，每个真实节点对应 replicas 个虚拟节点，虚拟节点的名称是 strconv.Itoa(i) + key
+func (m *Map) Add(keys ...string) {
+	for _, key := range keys {
+		for i := 0; i < m.replicas; i++ {
+			hash := int(m.hash([]byte(strconv.Itoa(i) + key)))
+			m.keys = append(m.keys, hash)
+			m.hashMap[hash] = key
+		}
+	}
+	sort.Ints(m.keys)
+}
+
+// Remove 删除真实节点及其所有虚拟节点，只有原本属于这些节点的键会被重新映射
+func (m *Map) Remove(keys ...string) {
+	removed := make(map[int]bool)
+	for _, key := range keys {
+		for i := 0; i < m.replicas; i++ {
+			hash := int(m.hash([]byte(strconv.Itoa(i) + key)))
+			// 不同节点的虚拟节点发生哈希冲突时，只删除仍属于当前节点的映射
+			if m.hashMap[hash] == key {
+				delete(m.hashMap, hash)
+				removed[hash] = true
+			}
+		}
+	}
+	ring := m.keys[:0]
+	for _, hash := range m.keys {
+		if !removed[hash] {
+			ring = append(ring, hash)
+		}
+	}
+	m.keys = ring
+}
+
+// IsEmpty 判断哈希环上是否没有任何节点
+func (m *Map) IsEmpty() bool {
+	return len(m.keys) == 0
+}
+
+// Get 返回哈希环上离键最近的节点，即负责该键的真实节点，环为空时返回空字符串
+func (m *Map) Get(key string) string {
+	if len(m.keys) == 0 {
+		return ""
+	}
+	hash := int(m.hash([]byte(key)))
+	// 顺时针找到第一个大于等于 hash 的虚拟节点
+	idx := sort.Search(len(m.keys), func(i int) bool {
+		return m.keys[i] >= hash
+	})
+	// idx == len(m.keys) 时说明应选择 m.keys[0]，因为 m.keys 是一个环状结构
+	return m.hashMap[m.keys[idx%len(m.keys)]]
+}
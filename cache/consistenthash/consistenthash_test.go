@@ -0,0 +1,111 @@
+package consistenthash
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestHashing(t *testing.T) {
+	// 使用可预测的哈希函数，节点 "6" 的虚拟节点为 6、16、26，依此类推
+	hash := New(3, func(key []byte) uint32 {
+		i, _ := strconv.Atoi(string(key))
+		return uint32(i)
+	})
+
+	hash.Add("6", "4", "2")
+
+	testCases := map[string]string{
+		"2":  "2",
+		"11": "2",
+		"23": "4",
+		"27": "2",
+	}
+
+	for k, v := range testCases {
+		if hash.Get(k) != v {
+			t.Errorf("Asking for %s, should have yielded %s", k, v)
+		}
+	}
+
+	// 新增节点 8 之后，27 应该映射到 8
+	hash.Add("8")
+	testCases["27"] = "8"
+	for k, v := range testCases {
+		if hash.Get(k) != v {
+			t.Errorf("Asking for %s, should have yielded %s", k, v)
+		}
+	}
+
+	// 删除节点 8 之后，27 重新映射回 2
+	hash.Remove("8")
+	testCases["27"] = "2"
+	for k, v := range testCases {
+		if hash.Get(k) != v {
+			t.Errorf("Asking for %s, should have yielded %s", k, v)
+		}
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	hash := New(3, nil)
+	if !hash.IsEmpty() || hash.Get("key") != "" {
+		t.Fatalf("empty ring should own no keys")
+	}
+	hash.Add("peer")
+	hash.Remove("peer")
+	if !hash.IsEmpty() {
+		t.Fatalf("ring should be empty after removing the only peer")
+	}
+}
+
+// TestRemoveMovement 删除一个节点时，只有原本属于它的键会被重新映射
+func TestRemoveMovement(t *testing.T) {
+	peers := []string{"peer0", "peer1", "peer2", "peer3", "peer4"}
+	hash := New(50, nil)
+	hash.Add(peers...)
+
+	const n = 10000
+	before := make([]string, n)
+	for i := range before {
+		before[i] = hash.Get("key" + strconv.Itoa(i))
+	}
+
+	hash.Remove("peer2")
+	moved := 0
+	for i := range before {
+		after := hash.Get("key" + strconv.Itoa(i))
+		if after == "peer2" {
+			t.Fatalf("key%d still maps to the removed peer", i)
+		}
+		if before[i] != "peer2" && after != before[i] {
+			t.Fatalf("key%d owned by %s should not move, moved to %s", i, before[i], after)
+		}
+		if after != before[i] {
+			moved++
+		}
+	}
+	// 大约 1/5 的键属于被删除的节点
+	if moved < n/10 || moved > n*3/10 {
+		t.Fatalf("expect about %d keys to move, got %d", n/5, moved)
+	}
+}
+
+// TestBalance 虚拟节点足够多时，各节点负责的键数量应接近平均值
+func TestBalance(t *testing.T) {
+	peers := []string{"peer0", "peer1", "peer2", "peer3", "peer4"}
+	hash := New(100, nil)
+	hash.Add(peers...)
+
+	const n = 100000
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		counts[hash.Get("key"+strconv.Itoa(i))]++
+	}
+	mean := float64(n) / float64(len(peers))
+	for _, p := range peers {
+		if deviation := math.Abs(float64(counts[p])-mean) / mean; deviation > 0.25 {
+			t.Errorf("peer %s owns %d keys, deviates %.0f%% from the mean %.0f", p, counts[p], deviation*100, mean)
+		}
+	}
+}
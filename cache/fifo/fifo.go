@@ -0,0 +1,93 @@
+package fifo
+
+import (
+	"cache/lru"
+	"container/list"
+)
+
+// Cache 是先进先出（FIFO）淘汰策略的缓存，访问记录不会改变它的淘汰顺序
+type Cache struct {
+	maxBytes  int64                         // 允许使用的最大内存，0 表示不限制
+	nbytes    int64                         // 当前已使用的内存
+	ll        *list.List                    // 双向链表，队首是最新写入的记录
+	cache     map[string]*list.Element      // 键是字符串，值是双向链表中对应节点的指针
+	OnEvicted func(key string, value Value) // 某条记录被移除时的回调函数，可以为 nil
+}
+
+type entry struct {
+	key   string
+	value Value
+}
+
+// Value 与 lru.Value 相同，使不同淘汰策略的缓存可以互相替换
+type Value = lru.Value
+
+// New 是Cache的构造方法，用来实例化Cache
+func New(maxBytes int64, onEvicted func(string, Value)) *Cache {
+	return &Cache{
+		maxBytes:  maxBytes,
+		ll:        list.New(),
+		cache:     make(map[string]*list.Element),
+		OnEvicted: onEvicted,
+	}
+}
+
+// Get 查找键对应的值，与 LRU 不同，命中不会把记录移动到队首
+func (c *Cache) Get(key string) (value Value, ok bool) {
+	if ele, ok := c.cache[key]; ok {
+		return ele.Value.(*entry).value, true
+	}
+	return
+}
+
+// RemoveOldest 移除最早写入的记录
+func (c *Cache) RemoveOldest() {
+	if ele := c.ll.Back(); ele != nil {
+		c.removeElement(ele)
+	}
+}
+
+// Remove 删除键对应的记录，返回记录是否存在
+func (c *Cache) Remove(key string) bool {
+	if ele, ok := c.cache[key]; ok {
+		c.removeElement(ele)
+		return true
+	}
+	return false
+}
+
+func (c *Cache) removeElement(ele *list.Element) {
+	c.ll.Remove(ele)
+	kv := ele.Value.(*entry)
+	delete(c.cache, kv.key)
+	c.nbytes -= int64(len(kv.key)) + int64(kv.value.Len())
+	if c.OnEvicted != nil {
+		c.OnEvicted(kv.key, kv.value)
+	}
+}
+
+// Add 新增或更新一条记录，更新已有的键不会改变它的写入顺序
+func (c *Cache) Add(key string, value Value) {
+	if ele, ok := c.cache[key]; ok {
+		kv := ele.Value.(*entry)
+		c.nbytes += int64(value.Len()) - int64(kv.value.Len())
+		kv.value = value
+	} else {
+		ele := c.ll.PushFront(&entry{key: key, value: value})
+		c.cache[key] = ele
+		c.nbytes += int64(len(key)) + int64(value.Len())
+	}
+	for c.maxBytes != 0 && c.maxBytes < c.nbytes {
+		c.RemoveOldest()
+	}
+}
+
+// Len 返回缓存中条目的数量
+func (c *Cache) Len() int {
+	return c.ll.Len()
+}
+
+// Bytes 返回缓存当前已使用的内存
+func (c *Cache) Bytes() int64 {
+	return c.nbytes
+}
@@ -0,0 +1,31 @@
+package fifo
+
+import (
+	"reflect"
+	"testing"
+)
+
+type String string
+
+func (d String) Len() int {
+	return len(d)
+}
+
+func TestRemoveOldest(t *testing.T) {
+	keys := make([]string, 0)
+	c := New(int64(8), func(key string, value Value) {
+		keys = append(keys, key)
+	})
+	c.Add("k1", String("v1"))
+	c.Add("k2", String("v2"))
+	// 访问不会改变先进先出的顺序
+	c.Get("k1")
+	c.Add("k3", String("v3"))
+
+	if _, ok := c.Get("k1"); ok || c.Len() != 2 {
+		t.Fatalf("RemoveOldest k1 failed")
+	}
+	if !reflect.DeepEqual([]string{"k1"}, keys) {
+		t.Fatalf("Call OnEvicted failed, expect keys equals to %s", []string{"k1"})
+	}
+}
@@ -0,0 +1,321 @@
+package cache
+
+import (
+	"cache/lru"
+	"cache/singleflight"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	batchLoadConcurrency = 16                    // GetMany 同时加载未命中键的最大并发数
+	invalidateAttempts   = 3                     // 通知每个远程节点删除键的最大尝试次数
+	invalidateBackoff    = 50 * time.Millisecond // 第一次重试前的等待时间，之后每次翻倍
+)
+
+// Getter 在缓存未命中时从数据源加载键对应的数据
+type Getter interface {
+	Get(key string) ([]byte, error)
+}
+
+// GetterFunc 是函数类型的 Getter，使普通函数也可以作为 Getter 使用
+type GetterFunc func(key string) ([]byte, error)
+
+// Get 调用函数本身，实现 Getter 接口
+func (f GetterFunc) Get(key string) ([]byte, error) {
+	return f(key)
+}
+
+// Group 是一个带名字的缓存命名空间，负责“查缓存，未命中时从数据源加载并写回缓存”的流程
+type Group struct {
+	name      string // 缓存的名字，用于在全局注册表中查找
+	getter    Getter // 缓存未命中时获取源数据的回调
+	mainCache *Cache // 并发安全的本地缓存，保存当前节点负责的键
+	// hotCache 保存从远程节点获取的热点键，避免热点键的请求全部落到同一个节点上，为 nil 表示未开启
+	hotCache *Cache
+	// hotSample 决定一次远程获取的结果是否写入 hotCache
+	hotSample func(key string) bool
+	// negCache 保存加载失败的结果，为 nil 表示未开启
+	negCache *negativeCache
+	peers    PeerPicker
+	// loader 保证同一个键的并发加载只会调用一次 getter
+	loader *singleflight.Group
+}
+
+var (
+	mu     sync.RWMutex
+	groups = make(map[string]*Group)
+)
+
+// NewGroup 创建一个 Group 并注册到全局注册表中，同名的 Group 会被覆盖
+func NewGroup(name string, cacheBytes int64, getter Getter) *Group {
+	g := newGroup(name, cacheBytes, getter)
+	mu.Lock()
+	defer mu.Unlock()
+	groups[name] = g
+	return g
+}
+
+// newGroup 创建一个不注册到全局注册表的 Group
+func newGroup(name string, cacheBytes int64, getter Getter) *Group {
+	if getter == nil {
+		panic("nil Getter")
+	}
+	return &Group{
+		name:      name,
+		getter:    getter,
+		mainCache: New(cacheBytes, nil),
+		loader:    &singleflight.Group{},
+	}
+}
+
+// SampleRandom 返回按 1/n 的概率采样的策略，访问越频繁的键越容易被采样到
+func SampleRandom(n int) func(key string) bool {
+	return func(key string) bool {
+		return n <= 1 || rand.Intn(n) == 0
+	}
+}
+
+// SetHotCache 开启热点缓存，cacheBytes 是热点缓存允许使用的最大内存，通常远小于主缓存
+// sample 决定哪些远程获取的结果需要保存，为 nil 时使用 SampleRandom(10)；需要在使用 Group 之前调用
+func (g *Group) SetHotCache(cacheBytes int64, sample func(key string) bool) {
+	if sample == nil {
+		sample = SampleRandom(10)
+	}
+	g.hotCache = New(cacheBytes, nil)
+	g.hotSample = sample
+}
+
+// GetGroup 返回指定名字的 Group，不存在时返回 nil
+func GetGroup(name string) *Group {
+	mu.RLock()
+	defer mu.RUnlock()
+	return groups[name]
+}
+
+// Name 返回 Group 的名字
+func (g *Group) Name() string {
+	return g.name
+}
+
+// RegisterPeers 注册用于选择远程节点的 PeerPicker，只能调用一次
+func (g *Group) RegisterPeers(peers PeerPicker) {
+	if g.peers != nil {
+		panic("RegisterPeers called more than once")
+	}
+	g.peers = peers
+}
+
+// Get 从缓存中读取键对应的值，未命中时先向负责该键的远程节点请求，失败后再调用 Getter 从本地加载
+func (g *Group) Get(key string) (ByteView, error) {
+	return g.get(key, true)
+}
+
+// getForPeer 响应远程节点的请求，未命中时只在本地加载
+func (g *Group) getForPeer(key string) (ByteView, error) {
+	return g.get(key, false)
+}
+
+func (g *Group) get(key string, usePeers bool) (ByteView, error) {
+	if key == "" {
+		return ByteView{}, fmt.Errorf("key is required")
+	}
+	if v, ok := g.mainCache.GetView(key); ok {
+		return v, nil
+	}
+	value, _, err := g.getMiss(key, usePeers, true)
+	return value, err
+}
+
+// getMiss 在主缓存未命中后依次查找热点缓存和负缓存，仍未命中时加载
+// populate 为 false 时本地加载的结果不写入主缓存，而是通过 pending 告诉调用方由它负责写入
+func (g *Group) getMiss(key string, usePeers bool, populate bool) (value ByteView, pending bool, err error) {
+	if g.hotCache != nil {
+		if v, ok := g.hotCache.GetView(key); ok {
+			return v, false, nil
+		}
+	}
+	if g.negCache != nil {
+		if v, ok := g.negCache.get(key); ok {
+			return ByteView{}, false, v.err
+		}
+	}
+	return g.load(key, usePeers, populate)
+}
+
+// Result 是 GetMany 中单个键的查询结果
+type Result struct {
+	Value ByteView
+	Err   error
+	Hit   bool // 是否直接命中主缓存，未命中的键需要经过热点缓存、负缓存或加载
+}
+
+// GetMany 批量读取多个键，返回的结果与 keys 一一对应，适合在启动时预热缓存
+// 主缓存只加锁查找一次，未命中的键按 Get 的流程并发加载，同时加载的键不超过 batchLoadConcurrency 个，
+// 本地加载的结果在全部加载结束后通过一次 AddMany 写回主缓存，只加锁和淘汰一次
+func (g *Group) GetMany(keys []string) []Result {
+	results := make([]Result, len(keys))
+	pending := make([]bool, len(keys))
+	values, hits := g.mainCache.GetMany(keys)
+	sem := make(chan struct{}, batchLoadConcurrency)
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		if key == "" {
+			results[i].Err = fmt.Errorf("key is required")
+			continue
+		}
+		if hits[i] {
+			if v, ok := values[i].(ByteView); ok {
+				results[i] = Result{Value: v, Hit: true}
+				continue
+			}
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, key string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i].Value, pending[i], results[i].Err = g.getMiss(key, true, false)
+		}(i, key)
+	}
+	wg.Wait()
+
+	var entries []lru.Entry
+	for i, key := range keys {
+		if pending[i] {
+			entries = append(entries, lru.Entry{Key: key, Value: results[i].Value})
+		}
+	}
+	if len(entries) > 0 {
+		g.mainCache.AddMany(entries)
+	}
+	return results
+}
+
+// loaded 是一次加载的结果，pending 表示结果是本地加载的，但还没有写入主缓存
+type loaded struct {
+	view    ByteView
+	pending bool
+}
+
+// load 加载未命中的键，同一时刻对同一个键的多次加载会被合并成一次
+// 合并后只有真正执行加载的调用决定是否写入主缓存；由 GetMany 执行时，主缓存在它的批次结束后才会更新
+func (g *Group) load(key string, usePeers bool, populate bool) (value ByteView, pending bool, err error) {
+	v, err := g.loader.Do(key, func() (interface{}, error) {
+		if usePeers && g.peers != nil {
+			if peer, ok := g.peers.PickPeer(key); ok {
+				if value, err := g.getFromPeer(peer, key); err == nil {
+					return loaded{view: value}, nil
+				} else {
+					log.Println("[Cache] Failed to get from peer", err)
+				}
+			}
+		}
+		value, err := g.getLocally(key)
+		if err != nil {
+			return nil, err
+		}
+		if !populate {
+			return loaded{view: value, pending: true}, nil
+		}
+		g.populateCache(key, value)
+		return loaded{view: value}, nil
+	})
+	if err == nil {
+		l := v.(loaded)
+		return l.view, l.pending, nil
+	}
+	return
+}
+
+// getFromPeer 从远程节点获取数据，远程节点负责该键，因此结果不写入主缓存，只按采样写入热点缓存
+func (g *Group) getFromPeer(peer PeerGetter, key string) (ByteView, error) {
+	bytes, err := peer.Get(g.name, key)
+	if err != nil {
+		return ByteView{}, err
+	}
+	// PeerGetter 的实现可能会复用返回的切片，拷贝之后再保存到热点缓存
+	value := NewByteView(bytes)
+	if g.hotCache != nil && g.hotSample(key) {
+		g.hotCache.AddView(key, value)
+	}
+	return value, nil
+}
+
+// getLocally 调用 Getter 从本地数据源获取数据，由调用方决定何时写入主缓存
+func (g *Group) getLocally(key string) (ByteView, error) {
+	bytes, err := g.getter.Get(key)
+	if err != nil {
+		if g.negCache != nil {
+			g.negCache.add(key, err)
+		}
+		return ByteView{}, err
+	}
+	return NewByteView(bytes), nil
+}
+
+func (g *Group) populateCache(key string, value ByteView) {
+	g.mainCache.AddView(key, value)
+}
+
+// Invalidate 在数据源中的值发生变化后删除所有节点上的副本
+// 先删除本地的主缓存、热点缓存和负缓存，再并发通知所有远程节点删除，
+// 每个节点失败后会重试，保证至少送达一次；重试耗尽仍失败的节点会在返回的错误中列出
+func (g *Group) Invalidate(key string) error {
+	if key == "" {
+		return fmt.Errorf("key is required")
+	}
+	g.removeLocally(key)
+
+	lister, ok := g.peers.(PeerLister)
+	if !ok {
+		return nil
+	}
+	peers := lister.ListPeers()
+	errs := make([]error, len(peers))
+	var wg sync.WaitGroup
+	for i, peer := range peers {
+		deleter, ok := peer.(PeerDeleter)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, deleter PeerDeleter) {
+			defer wg.Done()
+			errs[i] = g.deleteFromPeer(deleter, key)
+		}(i, deleter)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// deleteFromPeer 通知远程节点删除键，失败时按指数退避重试
+func (g *Group) deleteFromPeer(peer PeerDeleter, key string) (err error) {
+	backoff := invalidateBackoff
+	for i := 0; i < invalidateAttempts; i++ {
+		if i > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		if err = peer.Delete(g.name, key); err == nil {
+			return nil
+		}
+	}
+	log.Println("[Cache] Failed to invalidate on peer", err)
+	return err
+}
+
+// removeLocally 删除当前节点上键的所有副本
+func (g *Group) removeLocally(key string) {
+	g.mainCache.Remove(key)
+	if g.hotCache != nil {
+		g.hotCache.Remove(key)
+	}
+	if g.negCache != nil {
+		g.negCache.remove(key)
+	}
+}
@@ -0,0 +1,238 @@
+package cache
+
+import (
+	"cache/lru"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var db = map[string]string{
+	"Tom":  "630",
+	"Jack": "589",
+	"Sam":  "567",
+}
+
+func TestGetter(t *testing.T) {
+	var f Getter = GetterFunc(func(key string) ([]byte, error) {
+		return []byte(key), nil
+	})
+
+	expect := []byte("key")
+	if v, _ := f.Get("key"); !reflect.DeepEqual(v, expect) {
+		t.Errorf("callback failed")
+	}
+}
+
+func TestGroupGet(t *testing.T) {
+	loadCounts := make(map[string]int, len(db))
+	g := NewGroup("scores", 2<<10, GetterFunc(
+		func(key string) ([]byte, error) {
+			if v, ok := db[key]; ok {
+				loadCounts[key]++
+				return []byte(v), nil
+			}
+			return nil, fmt.Errorf("%s not exist", key)
+		}))
+
+	for k, v := range db {
+		if view, err := g.Get(k); err != nil || view.String() != v {
+			t.Fatalf("failed to get value of %s", k)
+		}
+		if _, err := g.Get(k); err != nil || loadCounts[k] > 1 {
+			t.Fatalf("cache %s miss", k)
+		}
+	}
+
+	if view, err := g.Get("unknown"); err == nil {
+		t.Fatalf("the value of unknow should be empty, but %s got", view)
+	}
+	if _, err := g.Get(""); err == nil {
+		t.Fatalf("empty key should be rejected")
+	}
+}
+
+func TestGetGroup(t *testing.T) {
+	groupName := "scores"
+	NewGroup(groupName, 2<<10, GetterFunc(
+		func(key string) (bytes []byte, err error) { return }))
+	if group := GetGroup(groupName); group == nil || group.Name() != groupName {
+		t.Fatalf("group %s not exist", groupName)
+	}
+
+	if group := GetGroup(groupName + "111"); group != nil {
+		t.Fatalf("expect nil, but %s got", group.Name())
+	}
+}
+
+func TestGroupGetConcurrent(t *testing.T) {
+	var loads int32
+	release := make(chan struct{})
+	g := NewGroup("concurrent", 2<<10, GetterFunc(
+		func(key string) ([]byte, error) {
+			atomic.AddInt32(&loads, 1)
+			<-release
+			return []byte(db[key]), nil
+		}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if view, err := g.Get("Tom"); err != nil || view.String() != "630" {
+				t.Errorf("failed to get value of Tom")
+			}
+		}()
+	}
+	// 等待所有 goroutine 进入加载流程后再让 getter 返回
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&loads); n != 1 {
+		t.Fatalf("getter should be called once, got %d", n)
+	}
+}
+
+func TestNegativeCache(t *testing.T) {
+	var loads int32
+	g := newGroup("negative", 2<<10, GetterFunc(
+		func(key string) ([]byte, error) {
+			atomic.AddInt32(&loads, 1)
+			if v, ok := db[key]; ok {
+				return []byte(v), nil
+			}
+			return nil, fmt.Errorf("%s not exist", key)
+		}))
+	g.SetNegativeCache(50*time.Millisecond, 10)
+
+	for i := 0; i < 3; i++ {
+		if _, err := g.Get("unknown"); err == nil || err.Error() != "unknown not exist" {
+			t.Fatalf("expect cached error, got %v", err)
+		}
+	}
+	if n := atomic.LoadInt32(&loads); n != 1 {
+		t.Fatalf("negative result should be cached, got %d loads", n)
+	}
+	if g.mainCache.Len() != 0 {
+		t.Fatalf("negative result should not be stored in the main cache")
+	}
+	if s, _ := g.mainCache.Stats(); s.Hits != 0 {
+		t.Fatalf("negative hits should not count as main cache hits, got %d", s.Hits)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	g.Get("unknown")
+	if n := atomic.LoadInt32(&loads); n != 2 {
+		t.Fatalf("expired negative result should be loaded again, got %d loads", n)
+	}
+}
+
+// TestNegativeCacheZeroTTL ttl 为 0 时不缓存加载错误
+func TestNegativeCacheZeroTTL(t *testing.T) {
+	var loads int32
+	g := newGroup("negative-zero", 2<<10, GetterFunc(
+		func(key string) ([]byte, error) {
+			atomic.AddInt32(&loads, 1)
+			return nil, fmt.Errorf("%s not exist", key)
+		}))
+	g.SetNegativeCache(0, 10)
+
+	for i := 0; i < 3; i++ {
+		if _, err := g.Get("unknown"); err == nil {
+			t.Fatalf("expect loader error")
+		}
+	}
+	if n := atomic.LoadInt32(&loads); n != 3 {
+		t.Fatalf("errors should not be cached with a zero ttl, got %d loads", n)
+	}
+}
+
+// countingPolicy 记录 Add 和 AddMany 的调用次数
+type countingPolicy struct {
+	*lru.Cache
+	adds, addManys int
+}
+
+func (p *countingPolicy) Add(key string, value lru.Value) {
+	p.adds++
+	p.Cache.Add(key, value)
+}
+
+func (p *countingPolicy) AddMany(entries []lru.Entry) []error {
+	p.addManys++
+	return p.Cache.AddMany(entries)
+}
+
+func TestGroupGetMany(t *testing.T) {
+	var loads int32
+	g := newGroup("batch", 2<<10, GetterFunc(
+		func(key string) ([]byte, error) {
+			atomic.AddInt32(&loads, 1)
+			if v, ok := db[key]; ok {
+				return []byte(v), nil
+			}
+			return nil, fmt.Errorf("%s not exist", key)
+		}))
+	policy := &countingPolicy{Cache: lru.New(2<<10, nil)}
+	g.mainCache = NewWithPolicy(policy)
+	g.Get("Tom")
+
+	keys := []string{"Tom", "Jack", "unknown", "", "Sam"}
+	results := g.GetMany(keys)
+	if !results[0].Hit || results[0].Value.String() != "630" {
+		t.Fatalf("expect Tom hit main cache, got %+v", results[0])
+	}
+	if results[1].Hit || results[1].Err != nil || results[1].Value.String() != "589" {
+		t.Fatalf("expect Jack loaded, got %+v", results[1])
+	}
+	if results[2].Err == nil || results[3].Err == nil {
+		t.Fatalf("expect errors for unknown and empty keys, got %+v %+v", results[2], results[3])
+	}
+	if results[4].Value.String() != "567" || atomic.LoadInt32(&loads) != 4 {
+		t.Fatalf("unexpected result %+v with %d loads", results[4], loads)
+	}
+	// 加载的 Jack 和 Sam 通过一次 AddMany 写回，不再逐条 Add
+	if policy.adds != 1 || policy.addManys != 1 {
+		t.Fatalf("expect a single write-back, got %d adds and %d AddMany calls", policy.adds, policy.addManys)
+	}
+
+	// 第二次批量读取时已加载的键全部命中
+	for i, r := range g.GetMany([]string{"Jack", "Sam"}) {
+		if !r.Hit {
+			t.Fatalf("key %d should hit main cache", i)
+		}
+	}
+}
+
+// reusingPeer 是每次都复用同一块缓冲区返回结果的 PeerGetter
+type reusingPeer struct {
+	buf []byte
+}
+
+func (p *reusingPeer) PickPeer(key string) (PeerGetter, bool) {
+	return p, true
+}
+
+func (p *reusingPeer) Get(group string, key string) ([]byte, error) {
+	p.buf = append(p.buf[:0], key...)
+	return p.buf, nil
+}
+
+func TestGetFromPeerCopies(t *testing.T) {
+	g := newGroup("peer-copy", 2<<10, GetterFunc(
+		func(key string) ([]byte, error) { return nil, fmt.Errorf("%s not local", key) }))
+	g.SetHotCache(1<<10, SampleRandom(1))
+	g.RegisterPeers(&reusingPeer{})
+
+	if v, err := g.Get("Tom"); err != nil || v.String() != "Tom" {
+		t.Fatalf("failed to get Tom from peer, got %v %v", v, err)
+	}
+	g.Get("Sam")
+	if v, ok := g.hotCache.GetView("Tom"); !ok || v.String() != "Tom" {
+		t.Fatalf("hot cache value should not change when the peer reuses its buffer, got %q", v.String())
+	}
+}
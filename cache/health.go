@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	healthPath           = "_health"   // 健康检查的地址，完整路径为 /<basepath>/_health
+	defaultProbeInterval = time.Second // 未设置 ProbeInterval 时主动探测的间隔
+)
+
+// HealthOptions 配置远程节点的健康检查
+type HealthOptions struct {
+	Timeout       time.Duration // 访问远程节点的超时时间，0 表示不超时
+	MaxFailures   int           // 连续失败多少次后把节点从哈希环中摘除，小于 1 时按 1 处理
+	ProbeInterval time.Duration // 主动探测所有远程节点的间隔，小于等于 0 时使用 defaultProbeInterval
+}
+
+// peerHealth 记录一个远程节点的健康状况
+type peerHealth struct {
+	failures int  // 连续失败的次数
+	healthy  bool // 是否在哈希环上
+}
+
+// SetHealthCheck 开启健康检查
+// 访问远程节点超时、连接失败或返回 502/503/504 计为一次失败，连续失败 MaxFailures 次的节点会被临时移出哈希环，
+// 它负责的键改由其他节点或本地加载；后台每隔 ProbeInterval 探测一次所有远程节点，恢复的节点会被重新加入哈希环
+// 被摘除的节点不会再被 PickPeer 选中，只能靠探测恢复，因此开启健康检查时总会启动后台探测
+// 需要在开始处理请求之前调用，重复调用会替换之前的配置和后台探测，调用 Close 停止后台探测
+func (p *HTTPPool) SetHealthCheck(opts HealthOptions) {
+	if opts.MaxFailures < 1 {
+		opts.MaxFailures = 1
+	}
+	if opts.ProbeInterval <= 0 {
+		opts.ProbeInterval = defaultProbeInterval
+	}
+	p.Close()
+	p.mu.Lock()
+	p.healthOpts = &opts
+	p.client = &http.Client{Timeout: opts.Timeout}
+	for addr, getter := range p.httpGetters {
+		p.initGetter(addr, getter)
+	}
+	p.mu.Unlock()
+
+	p.stopProbe = make(chan struct{})
+	go p.probeLoop(opts.ProbeInterval, p.stopProbe)
+}
+
+// Close 停止后台的健康探测
+func (p *HTTPPool) Close() {
+	if p.stopProbe != nil {
+		close(p.stopProbe)
+		p.stopProbe = nil
+	}
+}
+
+// initGetter 根据健康检查的配置设置访问远程节点的客户端，调用方需要持有 p.mu
+func (p *HTTPPool) initGetter(addr string, getter *httpGetter) {
+	getter.client = p.client
+	if p.healthOpts != nil && addr != p.self {
+		getter.report = func(ok bool) { p.report(addr, ok) }
+	}
+}
+
+// report 记录一次访问远程节点的结果，并在节点状态变化时更新哈希环
+func (p *HTTPPool) report(addr string, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	h, exist := p.health[addr]
+	if !exist {
+		return
+	}
+	if ok {
+		h.failures = 0
+		if !h.healthy {
+			h.healthy = true
+			p.peers.Add(addr)
+			p.Log("peer %s is healthy again", addr)
+		}
+		return
+	}
+	h.failures++
+	if h.healthy && h.failures >= p.healthOpts.MaxFailures {
+		h.healthy = false
+		p.peers.Remove(addr)
+		p.Log("peer %s is unhealthy after %d failures", addr, h.failures)
+	}
+}
+
+// healthy 判断远程节点当前是否在哈希环上
+func (p *HTTPPool) healthy(addr string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	h, ok := p.health[addr]
+	return ok && h.healthy
+}
+
+func (p *HTTPPool) probeLoop(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			addrs := make([]string, 0, len(p.health))
+			for addr := range p.health {
+				if addr != p.self {
+					addrs = append(addrs, addr)
+				}
+			}
+			client := p.client
+			p.mu.Unlock()
+			for _, addr := range addrs {
+				p.report(addr, p.probe(client, addr))
+			}
+		}
+	}
+}
+
+// probe 请求远程节点的健康检查地址，只要节点能够响应且状态码不是 502/503/504 就认为它是健康的
+func (p *HTTPPool) probe(client *http.Client, addr string) bool {
+	res, err := client.Get(addr + p.basePath + healthPath)
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+	if _, err := io.Copy(io.Discard, res.Body); err != nil {
+		log.Println("[Cache] Failed to read probe response", err)
+	}
+	return !unavailable(res.StatusCode)
+}
@@ -0,0 +1,291 @@
+package cache
+
+import (
+	"bytes"
+	"cache/consistenthash"
+	"cache/wire"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	defaultBasePath = "/_cache/"
+	defaultReplicas = 50
+)
+
+// HTTPPool 是基于 HTTP 的节点池，既作为服务端响应其他节点的请求，也作为 PeerPicker 选择远程节点
+type HTTPPool struct {
+	self        string                   // 当前节点的地址，例如 "http://localhost:8001"
+	basePath    string                   // 节点间通讯地址的前缀
+	mu          sync.Mutex               // 保护 peers、httpGetters 和 health
+	peers       *consistenthash.Map      // 一致性哈希环，用于根据键选择节点，只包含健康的节点
+	httpGetters map[string]*httpGetter   // 键是远程节点的地址，值是访问该节点的客户端
+	health      map[string]*peerHealth   // 键是节点的地址，值是节点的健康状况
+	healthOpts  *HealthOptions           // 健康检查的配置，为 nil 表示未开启
+	client      *http.Client             // 访问远程节点使用的客户端
+	stopProbe   chan struct{}            // 关闭后停止后台探测
+	getGroup    func(name string) *Group // 根据名字查找本地的 Group，默认为 GetGroup
+}
+
+// NewHTTPPool 创建以 self 为当前节点地址的 HTTPPool
+func NewHTTPPool(self string) *HTTPPool {
+	return &HTTPPool{
+		self:     self,
+		basePath: defaultBasePath,
+		client:   http.DefaultClient,
+		getGroup: GetGroup,
+	}
+}
+
+// Log 带上节点地址打印日志
+func (p *HTTPPool) Log(format string, v ...interface{}) {
+	log.Printf("[Server %s] %s", p.self, fmt.Sprintf(format, v...))
+}
+
+// ServeHTTP 处理节点间的请求，从本地的 Group 中读取数据
+// Content-Type 为 wire.ContentType 的请求使用二进制信封编码，其余按旧协议处理
+// /<basepath>/<group>/<key> 形式的请求，直接返回原始字节
+func (p *HTTPPool) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, p.basePath) {
+		http.Error(w, "HTTPPool serving unexpected path: "+r.URL.Path, http.StatusBadRequest)
+		return
+	}
+	if r.URL.Path == p.basePath+healthPath {
+		w.Write([]byte("ok"))
+		return
+	}
+	if r.Header.Get("Content-Type") == wire.ContentType {
+		p.serveWire(w, r)
+		return
+	}
+	// 请求路径的格式为 /<basepath>/<groupname>/<key>
+	parts := strings.SplitN(r.URL.Path[len(p.basePath):], "/", 2)
+	if len(parts) != 2 {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	groupName := parts[0]
+	key := parts[1]
+
+	group := p.getGroup(groupName)
+	if group == nil {
+		http.Error(w, "no such group: "+groupName, http.StatusNotFound)
+		return
+	}
+
+	// 其他节点认为键属于当前节点，因此只在本地加载，不再转发，避免节点视图不一致时请求循环
+	view, err := group.getForPeer(key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(view.ByteSlice())
+}
+
+// serveWire 处理二进制信封编码的请求，错误通过响应中的状态码返回，HTTP 状态码总是 200
+func (p *HTTPPool) serveWire(w http.ResponseWriter, r *http.Request) {
+	res := p.handleWire(r)
+	w.Header().Set("Content-Type", wire.ContentType)
+	w.Write(res.Marshal())
+}
+
+func (p *HTTPPool) handleWire(r *http.Request) *wire.Response {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return &wire.Response{Code: wire.CodeBadRequest, Error: err.Error()}
+	}
+	var req wire.Request
+	if err := req.Unmarshal(body); err != nil {
+		return &wire.Response{Code: wire.CodeBadRequest, Error: err.Error()}
+	}
+	group := p.getGroup(req.Group)
+	if group == nil {
+		return &wire.Response{Code: wire.CodeNotFound, Error: "no such group: " + req.Group}
+	}
+	switch req.Op {
+	case wire.OpGet:
+		view, err := group.getForPeer(req.Key)
+		if err != nil {
+			return &wire.Response{Code: wire.CodeError, Error: err.Error()}
+		}
+		return &wire.Response{Code: wire.CodeOK, Value: view.b}
+	case wire.OpDelete:
+		// 只删除本地的副本，不再广播，避免节点之间互相转发
+		group.removeLocally(req.Key)
+		return &wire.Response{Code: wire.CodeOK}
+	}
+	return &wire.Response{Code: wire.CodeBadRequest, Error: fmt.Sprintf("unknown op %d", req.Op)}
+}
+
+// Set 更新节点列表，peers 中可以包含当前节点自身
+func (p *HTTPPool) Set(peers ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.peers = consistenthash.New(defaultReplicas, nil)
+	p.peers.Add(peers...)
+	p.httpGetters = make(map[string]*httpGetter, len(peers))
+	p.health = make(map[string]*peerHealth, len(peers))
+	for _, peer := range peers {
+		getter := &httpGetter{baseURL: peer + p.basePath}
+		p.initGetter(peer, getter)
+		p.httpGetters[peer] = getter
+		p.health[peer] = &peerHealth{healthy: true}
+	}
+}
+
+// PickPeer 根据键选择节点，选中当前节点自身时 ok 为 false
+func (p *HTTPPool) PickPeer(key string) (PeerGetter, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.peers == nil {
+		return nil, false
+	}
+	if peer := p.peers.Get(key); peer != "" && peer != p.self {
+		return p.httpGetters[peer], true
+	}
+	return nil, false
+}
+
+// ListPeers 返回除当前节点以外的所有远程节点，包括暂时不健康的节点
+func (p *HTTPPool) ListPeers() []PeerGetter {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	peers := make([]PeerGetter, 0, len(p.httpGetters))
+	for addr, getter := range p.httpGetters {
+		if addr != p.self {
+			peers = append(peers, getter)
+		}
+	}
+	return peers
+}
+
+var _ PeerPicker = (*HTTPPool)(nil)
+var _ PeerLister = (*HTTPPool)(nil)
+
+// httpGetter 是访问远程节点的 HTTP 客户端
+type httpGetter struct {
+	baseURL string        // 远程节点的地址，例如 "http://example.com/_cache/"
+	legacy  atomic.Bool   // 远程节点是否只支持旧协议
+	client  *http.Client  // 发送请求使用的客户端，为 nil 时使用 http.DefaultClient
+	report  func(ok bool) // 报告一次请求是否成功，用于健康检查，可以为 nil
+}
+
+func (h *httpGetter) httpClient() *http.Client {
+	if h.client == nil {
+		return http.DefaultClient
+	}
+	return h.client
+}
+
+// observe 根据请求的结果报告节点的健康状况，只有连接失败、超时和 502/503/504 视为节点异常
+// 旧协议用 500 返回 Getter 的加载错误（例如数据源中不存在该键），这说明节点本身是正常的
+func (h *httpGetter) observe(res *http.Response, err error) {
+	if h.report != nil {
+		h.report(err == nil && !unavailable(res.StatusCode))
+	}
+}
+
+// unavailable 判断状态码是否表示节点或其前面的代理无法处理请求
+func unavailable(code int) bool {
+	switch code {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Get 请求远程节点，返回 group 中键对应的值
+// 优先使用二进制信封编码，远程节点不支持时自动退回旧协议，并在之后一直使用旧协议
+func (h *httpGetter) Get(group string, key string) ([]byte, error) {
+	if !h.legacy.Load() {
+		res, err := h.do(&wire.Request{Group: group, Key: key})
+		if err != nil {
+			return nil, err
+		}
+		if res != nil {
+			if res.Code != wire.CodeOK {
+				return nil, fmt.Errorf("server returned %v: %s", res.Code, res.Error)
+			}
+			return res.Value, nil
+		}
+		h.legacy.Store(true)
+	}
+	return h.getLegacy(group, key)
+}
+
+// Delete 通知远程节点删除 group 中的键，远程节点只支持旧协议或版本 1 的信封时返回错误
+func (h *httpGetter) Delete(group string, key string) error {
+	res, err := h.do(&wire.Request{Group: group, Key: key, Op: wire.OpDelete})
+	if err != nil {
+		return err
+	}
+	// 旧版本的节点会把删除请求当作读取请求处理，因此必须确认对方支持删除
+	if res == nil || res.Version < 2 {
+		return fmt.Errorf("peer %s does not support delete", h.baseURL)
+	}
+	if res.Code != wire.CodeOK {
+		return fmt.Errorf("server returned %v: %s", res.Code, res.Error)
+	}
+	return nil
+}
+
+// do 发送二进制信封编码的请求，远程节点只支持旧协议时返回 nil
+func (h *httpGetter) do(req *wire.Request) (*wire.Response, error) {
+	res, err := h.httpClient().Post(h.baseURL, wire.ContentType, bytes.NewReader(req.Marshal()))
+	h.observe(res, err)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.Header.Get("Content-Type") != wire.ContentType {
+		io.Copy(io.Discard, res.Body)
+		// 旧版本的节点无法解析路径中没有 key 的请求，会返回 400
+		if res.StatusCode == http.StatusBadRequest {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("server returned: %v", res.Status)
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %v", err)
+	}
+	var out wire.Response
+	if err := out.Unmarshal(body); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// getLegacy 使用旧协议 GET /<basepath>/<group>/<key> 请求远程节点
+func (h *httpGetter) getLegacy(group string, key string) ([]byte, error) {
+	u := fmt.Sprintf("%v%v/%v", h.baseURL, url.PathEscape(group), url.PathEscape(key))
+	res, err := h.httpClient().Get(u)
+	h.observe(res, err)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned: %v", res.Status)
+	}
+
+	bytes, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %v", err)
+	}
+	return bytes, nil
+}
+
+var _ PeerGetter = (*httpGetter)(nil)
+var _ PeerDeleter = (*httpGetter)(nil)
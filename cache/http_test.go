@@ -0,0 +1,540 @@
+package cache
+
+import (
+	"bytes"
+	"cache/wire"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testNode 是运行在 httptest 服务器上的一个缓存节点，每个节点持有自己的 Group
+type testNode struct {
+	server   *httptest.Server
+	pool     *HTTPPool
+	group    *Group
+	loads    int32 // 本节点 getter 被调用的次数
+	requests int32 // 本节点收到的 HTTP 请求数
+	failures int32 // 大于 0 时，接下来的这么多个请求都返回 503
+	handler  http.Handler
+}
+
+// restart 在原来的地址上重新启动被关闭的节点
+func (node *testNode) restart(t *testing.T) {
+	l, err := net.Listen("tcp", node.server.Listener.Addr().String())
+	if err != nil {
+		t.Skipf("cannot listen on %s again: %v", node.server.Listener.Addr(), err)
+	}
+	server := httptest.NewUnstartedServer(node.handler)
+	server.Listener.Close()
+	server.Listener = l
+	server.Start()
+	node.server = server
+}
+
+// newTestCluster 启动 n 个互为 peer 的节点，所有节点都提供名为 scores 的 Group
+func newTestCluster(t *testing.T, n int) []*testNode {
+	nodes := make([]*testNode, n)
+	addrs := make([]string, n)
+	for i := range nodes {
+		node := &testNode{}
+		node.group = newGroup("scores", 2<<10, GetterFunc(func(key string) ([]byte, error) {
+			atomic.AddInt32(&node.loads, 1)
+			if key == "unknown" {
+				return nil, fmt.Errorf("%s not exist", key)
+			}
+			return []byte("value-" + key), nil
+		}))
+		node.handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&node.requests, 1)
+			if atomic.AddInt32(&node.failures, -1) >= 0 {
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			node.pool.ServeHTTP(w, r)
+		})
+		node.server = httptest.NewServer(node.handler)
+		t.Cleanup(func() { node.server.Close() })
+		node.pool = NewHTTPPool(node.server.URL)
+		node.pool.getGroup = func(name string) *Group {
+			if name == node.group.Name() {
+				return node.group
+			}
+			return nil
+		}
+		nodes[i] = node
+		addrs[i] = node.server.URL
+	}
+	for _, node := range nodes {
+		node.pool.Set(addrs...)
+		node.group.RegisterPeers(node.pool)
+	}
+	return nodes
+}
+
+// owner 返回一致性哈希环上负责 key 的节点
+func owner(nodes []*testNode, key string) *testNode {
+	addr := nodes[0].pool.peers.Get(key)
+	for _, node := range nodes {
+		if node.server.URL == addr {
+			return node
+		}
+	}
+	return nil
+}
+
+func TestHTTPPoolServeHTTP(t *testing.T) {
+	NewGroup("http-scores", 2<<10, GetterFunc(func(key string) ([]byte, error) {
+		if v, ok := db[key]; ok {
+			return []byte(v), nil
+		}
+		return nil, fmt.Errorf("%s not exist", key)
+	}))
+	pool := NewHTTPPool("http://localhost:8001")
+
+	testCases := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/_cache/http-scores/Tom", http.StatusOK, "630"},
+		{"/_cache/http-scores/unknown", http.StatusInternalServerError, ""},
+		{"/_cache/no-such-group/Tom", http.StatusNotFound, ""},
+		{"/_cache/http-scores", http.StatusBadRequest, ""},
+		{"/other/http-scores/Tom", http.StatusBadRequest, ""},
+	}
+	for _, tc := range testCases {
+		w := httptest.NewRecorder()
+		pool.ServeHTTP(w, httptest.NewRequest("GET", tc.path, nil))
+		if w.Code != tc.code {
+			t.Fatalf("GET %s: expect status %d, got %d", tc.path, tc.code, w.Code)
+		}
+		if tc.body != "" && w.Body.String() != tc.body {
+			t.Fatalf("GET %s: expect body %s, got %s", tc.path, tc.body, w.Body.String())
+		}
+	}
+}
+
+func TestHTTPGetterEscape(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	getter := &httpGetter{baseURL: server.URL + defaultBasePath}
+	if _, err := getter.getLegacy("group", "a b/c+d"); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/_cache/group/a b/c+d" {
+		t.Fatalf("key should be escaped, server got path %s", path)
+	}
+}
+
+// TestGroupGetFromPeers 每个键只在负责它的节点上加载一次
+func TestGroupGetFromPeers(t *testing.T) {
+	nodes := newTestCluster(t, 3)
+
+	const n = 30
+	local := int32(0)
+	for i := 0; i < n; i++ {
+		key := "key" + strconv.Itoa(i)
+		for _, node := range nodes {
+			view, err := node.group.Get(key)
+			if err != nil || view.String() != "value-"+key {
+				t.Fatalf("failed to get value of %s, err %v", key, err)
+			}
+		}
+		if owner(nodes, key) == nodes[0] {
+			local++
+		}
+	}
+
+	var total int32
+	for _, node := range nodes {
+		total += atomic.LoadInt32(&node.loads)
+	}
+	if total != n {
+		t.Fatalf("each key should be loaded once by its owner, got %d loads for %d keys", total, n)
+	}
+	if loads := atomic.LoadInt32(&nodes[0].loads); loads != local {
+		t.Fatalf("node 0 should only load the %d keys it owns, got %d", local, loads)
+	}
+}
+
+// TestGroupGetPeerDown 远程节点不可用时回退到本地加载
+func TestGroupGetPeerDown(t *testing.T) {
+	nodes := newTestCluster(t, 2)
+	key := remoteKey(nodes)
+	nodes[1].server.Close()
+
+	view, err := nodes[0].group.Get(key)
+	if err != nil || view.String() != "value-"+key {
+		t.Fatalf("failed to get value of %s, err %v", key, err)
+	}
+	if loads := atomic.LoadInt32(&nodes[0].loads); loads != 1 {
+		t.Fatalf("node 0 should fall back to its local getter, got %d loads", loads)
+	}
+}
+
+func TestHTTPPoolServeWire(t *testing.T) {
+	NewGroup("wire-scores", 2<<10, GetterFunc(func(key string) ([]byte, error) {
+		if v, ok := db[key]; ok {
+			return []byte(v), nil
+		}
+		return nil, fmt.Errorf("%s not exist", key)
+	}))
+	pool := NewHTTPPool("http://localhost:8001")
+
+	testCases := []struct {
+		body  []byte
+		code  wire.Code
+		value string
+	}{
+		{(&wire.Request{Group: "wire-scores", Key: "Tom"}).Marshal(), wire.CodeOK, "630"},
+		{(&wire.Request{Group: "wire-scores", Key: "unknown"}).Marshal(), wire.CodeError, ""},
+		{(&wire.Request{Group: "no-such-group", Key: "Tom"}).Marshal(), wire.CodeNotFound, ""},
+		{[]byte("garbage"), wire.CodeBadRequest, ""},
+	}
+	for _, tc := range testCases {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", defaultBasePath, bytes.NewReader(tc.body))
+		r.Header.Set("Content-Type", wire.ContentType)
+		pool.ServeHTTP(w, r)
+
+		var res wire.Response
+		if err := res.Unmarshal(w.Body.Bytes()); err != nil {
+			t.Fatal(err)
+		}
+		if res.Code != tc.code || string(res.Value) != tc.value {
+			t.Fatalf("expect code %v value %q, got code %v value %q", tc.code, tc.value, res.Code, res.Value)
+		}
+	}
+}
+
+// TestHTTPGetterLegacyServer 新的客户端访问只支持旧协议的节点
+func TestHTTPGetterLegacyServer(t *testing.T) {
+	var posts, gets int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 模拟旧版本的 ServeHTTP：路径中没有 key 时返回 400
+		parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, defaultBasePath), "/", 2)
+		if r.Method == "POST" {
+			atomic.AddInt32(&posts, 1)
+		}
+		if len(parts) != 2 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		atomic.AddInt32(&gets, 1)
+		w.Header().Set("Content-Type", "application/octet-stream")
+		io.WriteString(w, db[parts[1]])
+	}))
+	defer server.Close()
+
+	getter := &httpGetter{baseURL: server.URL + defaultBasePath}
+	for i := 0; i < 2; i++ {
+		v, err := getter.Get("scores", "Tom")
+		if err != nil || string(v) != "630" {
+			t.Fatalf("failed to get Tom from legacy server, err %v", err)
+		}
+	}
+	if posts != 1 || gets != 2 {
+		t.Fatalf("expect 1 wire attempt then legacy requests, got %d posts and %d gets", posts, gets)
+	}
+}
+
+// TestHTTPGetterTransientError 代理返回的 503 等非信封响应不会让客户端永久退回旧协议
+func TestHTTPGetterTransientError(t *testing.T) {
+	var fail int32 = 1
+	nodes := newTestCluster(t, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&fail, -1) >= 0 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		nodes[0].pool.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	getter := &httpGetter{baseURL: server.URL + defaultBasePath}
+	if _, err := getter.Get("scores", "Tom"); err == nil {
+		t.Fatalf("expect an error for a 503 response")
+	}
+	if getter.legacy.Load() {
+		t.Fatalf("a transient 503 should not switch the getter to the legacy protocol")
+	}
+	if v, err := getter.Get("scores", "Tom"); err != nil || string(v) != "value-Tom" {
+		t.Fatalf("expect value-Tom after the peer recovers, got %q %v", v, err)
+	}
+}
+
+func TestHTTPGetterWireError(t *testing.T) {
+	nodes := newTestCluster(t, 1)
+	getter := &httpGetter{baseURL: nodes[0].server.URL + defaultBasePath}
+	if _, err := getter.Get("no-such-group", "Tom"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expect not found error, got %v", err)
+	}
+	if _, err := getter.Get("scores", "unknown"); err == nil || !strings.Contains(err.Error(), "unknown not exist") {
+		t.Fatalf("expect loader error, got %v", err)
+	}
+	if getter.legacy.Load() {
+		t.Fatalf("getter should keep using the wire protocol")
+	}
+}
+
+// remoteKey 返回一个不属于 nodes[0] 的键
+func remoteKey(nodes []*testNode) string {
+	for i := 0; ; i++ {
+		key := "key" + strconv.Itoa(i)
+		if owner(nodes, key) != nodes[0] {
+			return key
+		}
+	}
+}
+
+func TestHotCache(t *testing.T) {
+	nodes := newTestCluster(t, 2)
+	nodes[0].group.SetHotCache(1<<10, SampleRandom(1))
+	key := remoteKey(nodes)
+
+	for i := 0; i < 5; i++ {
+		if view, err := nodes[0].group.Get(key); err != nil || view.String() != "value-"+key {
+			t.Fatalf("failed to get value of %s, err %v", key, err)
+		}
+	}
+	if n := atomic.LoadInt32(&nodes[1].requests); n != 1 {
+		t.Fatalf("hot key should be fetched from its owner once, got %d requests", n)
+	}
+	if nodes[0].group.mainCache.Len() != 0 || nodes[0].group.hotCache.Len() != 1 {
+		t.Fatalf("remote value should only be kept in the hot cache")
+	}
+}
+
+func TestHotCacheSample(t *testing.T) {
+	nodes := newTestCluster(t, 2)
+	nodes[0].group.SetHotCache(1<<10, func(key string) bool { return false })
+	key := remoteKey(nodes)
+
+	for i := 0; i < 3; i++ {
+		nodes[0].group.Get(key)
+	}
+	if n := atomic.LoadInt32(&nodes[1].requests); n != 3 {
+		t.Fatalf("unsampled key should always be fetched from its owner, got %d requests", n)
+	}
+}
+
+// cached 判断节点的主缓存或热点缓存中是否有 key 的副本
+func (node *testNode) cached(key string) bool {
+	if _, ok := node.group.mainCache.Get(key); ok {
+		return true
+	}
+	_, ok := node.group.hotCache.Get(key)
+	return ok
+}
+
+func TestInvalidate(t *testing.T) {
+	nodes := newTestCluster(t, 3)
+	for _, node := range nodes {
+		node.group.SetHotCache(1<<10, SampleRandom(1))
+	}
+	key := "key0"
+	for _, node := range nodes {
+		node.group.Get(key)
+		if !node.cached(key) {
+			t.Fatalf("node %s should hold a copy of %s", node.server.URL, key)
+		}
+	}
+
+	// 其中一个远程节点第一次收到请求时失败，重试后成功
+	for _, node := range nodes[1:] {
+		if owner(nodes, key) != node {
+			atomic.StoreInt32(&node.failures, 1)
+			break
+		}
+	}
+	if err := nodes[0].group.Invalidate(key); err != nil {
+		t.Fatal(err)
+	}
+	for _, node := range nodes {
+		if node.cached(key) {
+			t.Fatalf("node %s should drop its copy of %s", node.server.URL, key)
+		}
+	}
+
+	// 删除之后重新从数据源加载
+	before := atomic.LoadInt32(&owner(nodes, key).loads)
+	nodes[0].group.Get(key)
+	if after := atomic.LoadInt32(&owner(nodes, key).loads); after != before+1 {
+		t.Fatalf("owner should reload %s after invalidation", key)
+	}
+}
+
+func TestInvalidatePeerDown(t *testing.T) {
+	nodes := newTestCluster(t, 2)
+	key := remoteKey(nodes)
+	nodes[0].group.mainCache.AddView(key, NewByteView([]byte("stale")))
+	nodes[1].server.Close()
+
+	if err := nodes[0].group.Invalidate(key); err == nil {
+		t.Fatalf("expect an error when a peer is unreachable")
+	}
+	if _, ok := nodes[0].group.mainCache.Get(key); ok {
+		t.Fatalf("local copy should be removed even if a peer is unreachable")
+	}
+}
+
+func TestHTTPGetterDeleteLegacyServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 模拟只支持版本 1 信封的节点：忽略 Op，按读取请求处理
+		w.Header().Set("Content-Type", wire.ContentType)
+		w.Write((&wire.Response{Version: 1, Code: wire.CodeOK, Value: []byte("630")}).Marshal())
+	}))
+	defer server.Close()
+
+	getter := &httpGetter{baseURL: server.URL + defaultBasePath}
+	if err := getter.Delete("scores", "Tom"); err == nil {
+		t.Fatalf("delete should fail on a peer without delete support")
+	}
+}
+
+// waitFor 轮询 cond，直到它返回 true 或超时
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met within 2s")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestHealthCheckFailover(t *testing.T) {
+	nodes := newTestCluster(t, 3)
+	pool := nodes[0].pool
+	pool.SetHealthCheck(HealthOptions{Timeout: time.Second, MaxFailures: 2})
+	defer pool.Close()
+
+	down := nodes[2]
+	key := ""
+	for i := 0; ; i++ {
+		key = "key" + strconv.Itoa(i)
+		if owner(nodes, key) == down {
+			break
+		}
+	}
+	down.server.Close()
+
+	// 连续失败达到 MaxFailures 之前，每次都会先请求不可用的节点，再回退到本地加载
+	for i := 0; i < 2; i++ {
+		nodes[0].group.mainCache.Remove(key)
+		if view, err := nodes[0].group.Get(key); err != nil || view.String() != "value-"+key {
+			t.Fatalf("failed to get value of %s, err %v", key, err)
+		}
+	}
+	if pool.healthy(down.server.URL) {
+		t.Fatalf("peer should be marked unhealthy after 2 failures")
+	}
+	for i := 0; i < 100; i++ {
+		if addr := pool.peers.Get("key" + strconv.Itoa(i)); addr == down.server.URL {
+			t.Fatalf("unhealthy peer should be removed from the ring")
+		}
+	}
+	// 摘除之后，原本属于它的键由其他节点负责
+	if view, err := nodes[0].group.Get(key); err != nil || view.String() != "value-"+key {
+		t.Fatalf("failed to get value of %s, err %v", key, err)
+	}
+}
+
+func TestHealthCheckRecover(t *testing.T) {
+	nodes := newTestCluster(t, 2)
+	pool := nodes[0].pool
+	pool.SetHealthCheck(HealthOptions{Timeout: time.Second, MaxFailures: 1, ProbeInterval: 20 * time.Millisecond})
+	defer pool.Close()
+
+	down := nodes[1]
+	key := remoteKey(nodes)
+	down.server.Close()
+	waitFor(t, func() bool { return !pool.healthy(down.server.URL) })
+	if _, ok := pool.PickPeer(key); ok {
+		t.Fatalf("no remote peer should be picked while the only peer is down")
+	}
+
+	down.restart(t)
+	waitFor(t, func() bool { return pool.healthy(down.server.URL) })
+	if _, ok := pool.PickPeer(key); !ok {
+		t.Fatalf("recovered peer should own its keys again")
+	}
+}
+
+func TestHealthCheckServerError(t *testing.T) {
+	nodes := newTestCluster(t, 2)
+	pool := nodes[0].pool
+	pool.SetHealthCheck(HealthOptions{MaxFailures: 2})
+	defer pool.Close()
+
+	// 偶发的一次 5xx 不会摘除节点，成功的请求会清零失败计数
+	key := remoteKey(nodes)
+	atomic.StoreInt32(&nodes[1].failures, 1)
+	nodes[0].group.Get(key)
+	nodes[0].group.mainCache.Remove(key)
+	nodes[0].group.Get(key)
+	atomic.StoreInt32(&nodes[1].failures, 1)
+	nodes[0].group.mainCache.Remove(key)
+	nodes[0].group.Get(key)
+	if !pool.healthy(nodes[1].server.URL) {
+		t.Fatalf("peer should stay healthy after non-consecutive failures")
+	}
+}
+
+// TestHealthCheckLoaderError 旧协议用 500 返回加载错误，健康的节点不会因此被摘除
+func TestHealthCheckLoaderError(t *testing.T) {
+	nodes := newTestCluster(t, 2)
+	pool := nodes[0].pool
+	pool.SetHealthCheck(HealthOptions{MaxFailures: 1})
+	defer pool.Close()
+
+	addr := nodes[1].server.URL
+	getter := pool.httpGetters[addr]
+	getter.legacy.Store(true)
+	for i := 0; i < 3; i++ {
+		if _, err := getter.Get("scores", "unknown"); err == nil {
+			t.Fatalf("expect loader error from peer")
+		}
+	}
+	if !pool.healthy(addr) {
+		t.Fatalf("peer returning loader errors should stay in the ring")
+	}
+}
+
+// TestHealthCheckDefaultProbe 未设置 ProbeInterval 时仍会探测，被摘除的节点恢复后能重新加入哈希环
+func TestHealthCheckDefaultProbe(t *testing.T) {
+	nodes := newTestCluster(t, 2)
+	pool := nodes[0].pool
+	// 重复调用会停止之前的后台探测
+	pool.SetHealthCheck(HealthOptions{MaxFailures: 1})
+	first := pool.stopProbe
+	pool.SetHealthCheck(HealthOptions{MaxFailures: 1})
+	defer pool.Close()
+	select {
+	case <-first:
+	default:
+		t.Fatalf("previous probe loop should be stopped")
+	}
+
+	down := nodes[1]
+	down.server.Close()
+	pool.report(down.server.URL, false)
+	if pool.healthy(down.server.URL) {
+		t.Fatalf("peer should be marked unhealthy")
+	}
+	down.restart(t)
+	waitFor(t, func() bool { return pool.healthy(down.server.URL) })
+}
@@ -0,0 +1,145 @@
+package lfu
+
+import (
+	"cache/lru"
+	"container/heap"
+)
+
+// Cache 是最不经常使用（LFU）淘汰策略的缓存
+// 记录按访问次数保存在小顶堆中，访问次数相同时优先淘汰最久未被访问的记录
+type Cache struct {
+	maxBytes  int64                         // 允许使用的最大内存，0 表示不限制
+	nbytes    int64                         // 当前已使用的内存
+	tick      uint64                        // 逻辑时钟，每次访问递增，用于打破访问次数相同的平局
+	queue     priorityQueue                 // 按 (访问次数, 最近访问时间) 排序的小顶堆
+	cache     map[string]*entry             // 键是字符串，值是堆中对应的记录
+	OnEvicted func(key string, value Value) // 某条记录被移除时的回调函数，可以为 nil
+}
+
+type entry struct {
+	key   string
+	value Value
+	freq  int    // 访问次数
+	tick  uint64 // 最近一次访问时的逻辑时钟
+	index int    // 在堆中的下标
+}
+
+// Value 与 lru.Value 相同，使不同淘汰策略的缓存可以互相替换
+type Value = lru.Value
+
+// New 是Cache的构造方法，用来实例化Cache
+func New(maxBytes int64, onEvicted func(string, Value)) *Cache {
+	return &Cache{
+		maxBytes:  maxBytes,
+		cache:     make(map[string]*entry),
+		OnEvicted: onEvicted,
+	}
+}
+
+// Get 查找键对应的值，命中时增加记录的访问次数
+func (c *Cache) Get(key string) (value Value, ok bool) {
+	if e, ok := c.cache[key]; ok {
+		c.touch(e)
+		return e.value, true
+	}
+	return
+}
+
+// touch 增加访问次数并刷新最近访问时间，然后调整记录在堆中的位置
+func (c *Cache) touch(e *entry) {
+	c.tick++
+	e.freq++
+	e.tick = c.tick
+	heap.Fix(&c.queue, e.index)
+}
+
+// RemoveOldest 移除访问次数最少的记录
+func (c *Cache) RemoveOldest() {
+	if c.queue.Len() > 0 {
+		c.removeEntry(c.queue[0])
+	}
+}
+
+// Remove 删除键对应的记录，返回记录是否存在
+func (c *Cache) Remove(key string) bool {
+	if e, ok := c.cache[key]; ok {
+		c.removeEntry(e)
+		return true
+	}
+	return false
+}
+
+func (c *Cache) removeEntry(e *entry) {
+	heap.Remove(&c.queue, e.index)
+	delete(c.cache, e.key)
+	c.nbytes -= int64(len(e.key)) + int64(e.value.Len())
+	if c.OnEvicted != nil {
+		c.OnEvicted(e.key, e.value)
+	}
+}
+
+// Add 新增或更新一条记录，更新已有的键也算作一次访问
+func (c *Cache) Add(key string, value Value) {
+	if e, ok := c.cache[key]; ok {
+		c.nbytes += int64(value.Len()) - int64(e.value.Len())
+		e.value = value
+		c.touch(e)
+	} else {
+		size := int64(len(key)) + int64(value.Len())
+		// 先腾出空间再写入，否则访问次数为 1 的新记录总会被立即淘汰
+		for c.maxBytes != 0 && c.queue.Len() > 0 && c.maxBytes < c.nbytes+size {
+			c.RemoveOldest()
+		}
+		c.tick++
+		e := &entry{key: key, value: value, freq: 1, tick: c.tick}
+		heap.Push(&c.queue, e)
+		c.cache[key] = e
+		c.nbytes += size
+	}
+	for c.maxBytes != 0 && c.maxBytes < c.nbytes {
+		c.RemoveOldest()
+	}
+}
+
+// Len 返回缓存中条目的数量
+func (c *Cache) Len() int {
+	return c.queue.Len()
+}
+
+// Bytes 返回缓存当前已使用的内存
+func (c *Cache) Bytes() int64 {
+	return c.nbytes
+}
+
+// priorityQueue 实现了 heap.Interface
+type priorityQueue []*entry
+
+func (q priorityQueue) Len() int { return len(q) }
+
+func (q priorityQueue) Less(i, j int) bool {
+	if q[i].freq == q[j].freq {
+		return q[i].tick < q[j].tick
+	}
+	return q[i].freq < q[j].freq
+}
+
+func (q priorityQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *priorityQueue) Push(x interface{}) {
+	e := x.(*entry)
+	e.index = len(*q)
+	*q = append(*q, e)
+}
+
+func (q *priorityQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return e
+}
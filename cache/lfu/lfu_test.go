@@ -0,0 +1,32 @@
+package lfu
+
+import (
+	"reflect"
+	"testing"
+)
+
+type String string
+
+func (d String) Len() int {
+	return len(d)
+}
+
+func TestRemoveOldest(t *testing.T) {
+	keys := make([]string, 0)
+	c := New(int64(8), func(key string, value Value) {
+		keys = append(keys, key)
+	})
+	c.Add("k1", String("v1"))
+	c.Add("k2", String("v2"))
+	c.Get("k1")
+	c.Get("k1")
+	c.Get("k2")
+	// k2 的访问次数更少，即使它更晚被访问也会先被淘汰
+	c.Add("k3", String("v3"))
+	// k3 只被访问过 1 次，先于 k1 被淘汰
+	c.Add("k4", String("v4"))
+
+	if !reflect.DeepEqual([]string{"k2", "k3"}, keys) {
+		t.Fatalf("Call OnEvicted failed, expect keys equals to %s, got %s", []string{"k2", "k3"}, keys)
+	}
+}
@@ -0,0 +1,124 @@
+package lru
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestAddMany(t *testing.T) {
+	keys := make([]string, 0)
+	lru := New(int64(12), func(key string, value Value) {
+		keys = append(keys, key)
+	})
+	lru.Add("k0", String("v0"))
+	errs := lru.AddMany([]Entry{
+		{"k1", String("v1")},
+		{"k2", String("v2")},
+		{"big", String("0123456789")},
+		{"k3", String("v3")},
+	})
+	if !reflect.DeepEqual([]error{nil, nil, ErrEntryTooLarge, nil}, errs) {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	// 与循环调用 Add 相同，只淘汰最老的 k0
+	if !reflect.DeepEqual([]string{"k0"}, keys) {
+		t.Fatalf("expect k0 evicted, got %s", keys)
+	}
+	if !reflect.DeepEqual([]string{"k3", "k2", "k1"}, lru.Keys()) || lru.Bytes() != 12 {
+		t.Fatalf("unexpected keys %s, bytes=%d", lru.Keys(), lru.Bytes())
+	}
+}
+
+// TestAddManyDuplicateKey 淘汰只在批次末尾进行，重复写入的键只按最后的大小计算
+func TestAddManyDuplicateKey(t *testing.T) {
+	lru := New(int64(10), nil)
+	lru.Add("x", String("1234"))
+	lru.AddMany([]Entry{{"A", String("12345")}, {"A", String("")}})
+	if !reflect.DeepEqual([]string{"A", "x"}, lru.Keys()) || lru.Bytes() != 6 {
+		t.Fatalf("expect x kept after deferred eviction, got %s, bytes=%d", lru.Keys(), lru.Bytes())
+	}
+	if s := lru.Stats(); s.Adds != 2 || s.Overwrites != 1 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
+
+func TestGetMany(t *testing.T) {
+	lru := New(int64(0), nil)
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+	values, ok := lru.GetMany([]string{"k1", "missing", "k2"})
+	if !reflect.DeepEqual([]bool{true, false, true}, ok) {
+		t.Fatalf("unexpected hits %v", ok)
+	}
+	if values[0] != String("v1") || values[1] != nil || values[2] != String("v2") {
+		t.Fatalf("unexpected values %v", values)
+	}
+	// 命中的键按 keys 的顺序移到链表头部
+	if !reflect.DeepEqual([]string{"k2", "k1"}, lru.Keys()) {
+		t.Fatalf("unexpected keys %s", lru.Keys())
+	}
+	if s := lru.Stats(); s.Hits != 2 || s.Misses != 1 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
+
+const batchSize = 1000
+
+func batchEntries() []Entry {
+	entries := make([]Entry, batchSize)
+	for i := range entries {
+		entries[i] = Entry{"key" + strconv.Itoa(i), String("value")}
+	}
+	return entries
+}
+
+func batchKeys() []string {
+	keys := make([]string, batchSize)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+	}
+	return keys
+}
+
+func BenchmarkAddLoop(b *testing.B) {
+	entries := batchEntries()
+	lru := New(int64(batchSize*8), nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, e := range entries {
+			lru.Add(e.Key, e.Value)
+		}
+	}
+}
+
+func BenchmarkAddMany(b *testing.B) {
+	entries := batchEntries()
+	lru := New(int64(batchSize*8), nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lru.AddMany(entries)
+	}
+}
+
+func BenchmarkGetLoop(b *testing.B) {
+	lru := New(int64(0), nil)
+	lru.AddMany(batchEntries())
+	keys := batchKeys()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, k := range keys {
+			lru.Get(k)
+		}
+	}
+}
+
+func BenchmarkGetMany(b *testing.B) {
+	lru := New(int64(0), nil)
+	lru.AddMany(batchEntries())
+	keys := batchKeys()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lru.GetMany(keys)
+	}
+}
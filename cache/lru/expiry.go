@@ -0,0 +1,31 @@
+package lru
+
+// expiryHeap 按过期时间排序的小顶堆，实现了 heap.Interface
+// 每个 entry 记录自己在堆中的下标，以便在删除或更新时直接定位
+type expiryHeap []*entry
+
+func (h expiryHeap) Len() int { return len(h) }
+
+func (h expiryHeap) Less(i, j int) bool { return h[i].expire.Before(h[j].expire) }
+
+func (h expiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *expiryHeap) Push(x interface{}) {
+	kv := x.(*entry)
+	kv.index = len(*h)
+	*h = append(*h, kv)
+}
+
+func (h *expiryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	kv := old[n-1]
+	old[n-1] = nil
+	kv.index = -1
+	*h = old[:n-1]
+	return kv
+}
@@ -1,65 +1,289 @@
 package lru
 
-import "container/list"
+import (
+	"container/heap"
+	"container/list"
+	"errors"
+	"time"
+)
+
+// ErrEntryTooLarge 表示单条记录的大小超过了整个缓存允许使用的最大内存
+var ErrEntryTooLarge = errors.New("lru: entry is larger than the cache")
+
+// EntryOverhead 是开启开销统计后每条记录额外计入的字节数
+// 包括链表节点、entry 结构体和映射中键的存储，数值通过对比 runtime.MemStats 的增长校准得到
+const EntryOverhead = 160
 
 type Cache struct {
-	maxBytes  int64                         // 允许使用的最大内存
-	nbytes    int64                         // 当前已使用的内存
-	ll        *list.List                    // 双向链表
-	cache     map[string]*list.Element      // 键是字符串，值是双向链表中对应节点的指针
-	OnEvicted func(key string, value Value) // 某条记录被移除时的回调函数，可以为 nil
+	maxBytes   int64                         // 允许使用的最大内存，0 表示不限制
+	maxEntries int                           // 允许保存的最大条目数，0 表示不限制
+	nbytes     int64                         // 当前已使用的内存
+	overhead   int64                         // 每条记录额外计入的字节数，未开启开销统计时为 0
+	ll         *list.List                    // 双向链表
+	cache      map[string]*list.Element      // 键是字符串，值是双向链表中对应节点的指针
+	expiries   expiryHeap                    // 按过期时间排序的小顶堆，只包含设置了过期时间的节点
+	now        func() time.Time              // 获取当前时间，测试时可以替换
+	stats      counters                      // 命中、写入和淘汰的统计
+	OnEvicted  func(key string, value Value) // 某条记录被移除时的回调函数，可以为 nil
+	// OnEvictedWithReason 与 OnEvicted 类似，但会同时报告记录离开缓存的原因，可以为 nil
+	// 两个回调可以同时设置；值被 Add 覆盖时只会触发 OnEvictedWithReason，OnEvicted 保持原有行为不触发
+	OnEvictedWithReason func(key string, value Value, reason EvictionReason)
 }
 
 // 键值对 entry 是双向链表节点的数据类型，在链表中仍保存每个值对应的 key 的好处在于，淘汰队首节点时，需要用 key 从字典中删除对应的映射
 type entry struct {
-	key   string
-	value Value
+	key    string
+	value  Value
+	expire time.Time // 过期时间，零值表示永不过期
+	index  int       // 在 expiries 堆中的下标，-1 表示不在堆中
 }
 
 type Value interface {
 	Len() int // 用于返回值所占用的内存大小
 }
 
+// Entry 是 AddMany 批量写入的一条记录
+type Entry struct {
+	Key   string
+	Value Value
+}
+
+// EvictionReason 表示一条记录离开缓存的原因
+type EvictionReason int
+
+const (
+	EvictedCapacity EvictionReason = iota // 超出容量限制，或调用 RemoveOldest 被淘汰
+	EvictedRemoved                        // 被调用方显式删除
+	EvictedReplaced                       // 旧值被 Add 写入的新值覆盖
+	EvictedExpired                        // 超过过期时间被回收
+)
+
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictedCapacity:
+		return "capacity"
+	case EvictedRemoved:
+		return "removed"
+	case EvictedReplaced:
+		return "replaced"
+	case EvictedExpired:
+		return "expired"
+	}
+	return "unknown"
+}
+
 // New 是Cache的构造方法，用来实例化Cache
 func New(maxBytes int64, onEvicted func(string, Value)) *Cache {
+	return NewWithLimits(maxBytes, 0, onEvicted)
+}
+
+// NewWithLimits 同时按内存和条目数限制缓存大小，任一参数为 0 表示不限制该项
+// 两项限制同时设置时，超出任意一项都会从链表尾部开始淘汰，直到两项都满足
+func NewWithLimits(maxBytes int64, maxEntries int, onEvicted func(string, Value)) *Cache {
 	return &Cache{
-		maxBytes:  maxBytes,
-		ll:        list.New(),
-		cache:     make(map[string]*list.Element),
-		OnEvicted: onEvicted,
+		maxBytes:   maxBytes,
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		cache:      make(map[string]*list.Element),
+		now:        time.Now,
+		OnEvicted:  onEvicted,
 	}
 }
 
 func (c *Cache) Get(key string) (value Value, ok bool) {
+	// 先回收已经过期的记录，堆顶未过期时只需一次比较
+	c.RemoveExpired()
 	// 从字段中找到对应的双向链表的节点
 	if ele, ok := c.cache[key]; ok {
 		// 使用链表的 MoveToFront 方法将该元素移动到链表的前端
 		c.ll.MoveToFront(ele)
 		// // 从链表节点中提取值，ele.Value 已经知道是 *entry 类型
 		kv := ele.Value.(*entry)
+		c.stats.hits.Add(1)
 		return kv.value, true
 	}
+	c.stats.misses.Add(1)
+	return
+}
+
+// GetMany 批量查找多个键，返回的 values 和 ok 与 keys 一一对应
+// 与循环调用 Get 的结果相同，但过期记录只在开始时回收一次
+func (c *Cache) GetMany(keys []string) (values []Value, ok []bool) {
+	c.RemoveExpired()
+	values = make([]Value, len(keys))
+	ok = make([]bool, len(keys))
+	for i, key := range keys {
+		if ele, hit := c.cache[key]; hit {
+			c.ll.MoveToFront(ele)
+			values[i], ok[i] = ele.Value.(*entry).value, true
+			c.stats.hits.Add(1)
+		} else {
+			c.stats.misses.Add(1)
+		}
+	}
+	return
+}
+
+// Peek 查找键对应的值，但不会将其标记为最近使用，已过期的记录视为未命中
+func (c *Cache) Peek(key string) (value Value, ok bool) {
+	if ele, ok := c.cache[key]; ok {
+		kv := ele.Value.(*entry)
+		if !c.expired(kv) {
+			return kv.value, true
+		}
+	}
 	return
 }
 
+// Oldest 返回最近最少使用的记录（可能已过期但尚未回收），不会改变记录的新旧顺序，缓存为空时 ok 为 false
+func (c *Cache) Oldest() (key string, value Value, ok bool) {
+	if ele := c.ll.Back(); ele != nil {
+		kv := ele.Value.(*entry)
+		return kv.key, kv.value, true
+	}
+	return
+}
+
+// Contains 判断缓存中是否存在未过期的键，不会改变记录的新旧顺序
+func (c *Cache) Contains(key string) bool {
+	_, ok := c.Peek(key)
+	return ok
+}
+
+// Keys 按从新到旧的顺序返回所有未过期的键
+func (c *Cache) Keys() []string {
+	keys := make([]string, 0, c.ll.Len())
+	for ele := c.ll.Front(); ele != nil; ele = ele.Next() {
+		kv := ele.Value.(*entry)
+		if !c.expired(kv) {
+			keys = append(keys, kv.key)
+		}
+	}
+	return keys
+}
+
+// Remove 删除键对应的记录，返回记录是否存在
+func (c *Cache) Remove(key string) bool {
+	if ele, ok := c.cache[key]; ok {
+		c.removeElement(ele, EvictedRemoved)
+		return true
+	}
+	return false
+}
+
+// Clear 清空缓存，notify 为 true 时会对每条记录触发淘汰回调
+func (c *Cache) Clear(notify bool) {
+	if notify {
+		// 从最老的记录开始逐条删除，回调顺序与容量淘汰一致
+		for ele := c.ll.Back(); ele != nil; ele = c.ll.Back() {
+			c.removeElement(ele, EvictedRemoved)
+		}
+		return
+	}
+	c.stats.evictions[EvictedRemoved].Add(uint64(c.ll.Len()))
+	c.ll.Init()
+	c.cache = make(map[string]*list.Element)
+	c.expiries = nil
+	c.nbytes = 0
+}
+
 // RemoveOldest 方法用于从缓存中移除最老的条目，即最近最少使用的条目
 func (c *Cache) RemoveOldest() {
 	// 获取双向链表中最后一个节点
 	ele := c.ll.Back()
 	if ele != nil {
-		// c.ll.Remove(ele) 从链表中移除这个最老的节点
-		c.ll.Remove(ele)
-		kv := ele.Value.(*entry)
-		// cache 映射中删除与 entry 的 key 相关联的条目，确保映射和链表同步
-		delete(c.cache, kv.key)
-		c.nbytes -= int64(len(kv.key)) + int64(kv.value.Len())
-		if c.OnEvicted != nil {
-			c.OnEvicted(kv.key, kv.value)
-		}
+		c.removeElement(ele, EvictedCapacity)
 	}
 }
 
+// RemoveExpired 移除所有已过期的记录，返回移除的条数
+// 过期记录按过期时间保存在小顶堆中，只需从堆顶开始检查，不会扫描整个链表
+func (c *Cache) RemoveExpired() int {
+	n := 0
+	now := c.now()
+	for len(c.expiries) > 0 && !c.expiries[0].expire.After(now) {
+		c.removeElement(c.cache[c.expiries[0].key], EvictedExpired)
+		n++
+	}
+	return n
+}
+
+// expired 判断记录是否已经过期
+func (c *Cache) expired(kv *entry) bool {
+	return !kv.expire.IsZero() && !kv.expire.After(c.now())
+}
+
+// removeElement 从链表、映射和过期堆中删除节点，并以 reason 触发淘汰回调
+func (c *Cache) removeElement(ele *list.Element, reason EvictionReason) {
+	// c.ll.Remove(ele) 从链表中移除这个节点
+	c.ll.Remove(ele)
+	kv := ele.Value.(*entry)
+	// cache 映射中删除与 entry 的 key 相关联的条目，确保映射和链表同步
+	delete(c.cache, kv.key)
+	if kv.index >= 0 {
+		heap.Remove(&c.expiries, kv.index)
+	}
+	c.nbytes -= c.entrySize(kv.key, kv.value)
+	c.stats.evictions[reason].Add(1)
+	if c.OnEvicted != nil {
+		c.OnEvicted(kv.key, kv.value)
+	}
+	if c.OnEvictedWithReason != nil {
+		c.OnEvictedWithReason(kv.key, kv.value, reason)
+	}
+}
+
+// Add 新增或更新一条永不过期的记录
+// 超过整个缓存大小的记录会被直接丢弃，不会淘汰已有的记录，需要感知这种情况时使用 TryAdd
 func (c *Cache) Add(key string, value Value) {
+	_ = c.add(key, value, time.Time{})
+}
+
+// TryAdd 与 Add 相同，但当记录的大小超过 maxBytes 时拒绝写入并返回 ErrEntryTooLarge
+// 被拒绝时其他记录保持不变；如果 key 已经存在，旧值会以 EvictedRemoved 的原因被删除，避免读到过时的数据
+func (c *Cache) TryAdd(key string, value Value) error {
+	return c.add(key, value, time.Time{})
+}
+
+// AddWithTTL 新增或更新一条记录，记录在 ttl 之后过期，ttl 小于等于 0 时永不过期
+// 过期的记录在 Get 时被视为未命中，并在后续的 Get、Add 或 RemoveExpired 中被回收
+func (c *Cache) AddWithTTL(key string, value Value, ttl time.Duration) {
+	var expire time.Time
+	if ttl > 0 {
+		expire = c.now().Add(ttl)
+	}
+	_ = c.add(key, value, expire)
+}
+
+// AddMany 批量写入多条永不过期的记录，返回的错误与 entries 一一对应，规则与 TryAdd 相同
+// 所有记录写入之后才统一淘汰一次，写入过程中缓存可以暂时超过容量，淘汰时同样从链表尾部开始
+// 因此结果可能与循环调用 Add 不同：批次中同一个键被多次写入时只按最后一次的大小计算，
+// 中间较大的值不会像逐条 Add 那样提前淘汰旧记录
+func (c *Cache) AddMany(entries []Entry) []error {
+	c.RemoveExpired()
+	errs := make([]error, len(entries))
+	for i, e := range entries {
+		errs[i] = c.set(e.Key, e.Value, time.Time{})
+	}
+	c.evict()
+	return errs
+}
+
+func (c *Cache) add(key string, value Value, expire time.Time) error {
+	// 先回收已经过期的记录，为新记录腾出空间
+	c.RemoveExpired()
+	err := c.set(key, value, expire)
+	c.evict()
+	return err
+}
+
+// set 写入一条记录但不检查容量限制，调用方需要在之后调用 evict
+func (c *Cache) set(key string, value Value, expire time.Time) error {
+	// 单条记录超过整个缓存的大小时，写入后会把所有记录连同自己一起淘汰，因此提前拒绝
+	if c.maxBytes != 0 && c.entrySize(key, value) > c.maxBytes {
+		c.Remove(key)
+		return ErrEntryTooLarge
+	}
 	// 尝试从 cache 映射中获取与 key 相关联的双向链表节点 *list.Element。如果 key 存在，ok 将为 true
 	if ele, ok := c.cache[key]; ok {
 		// 使用链表的 MoveToFront 方法将该元素移动到链表的前端，表示这个键是最近访问的
@@ -67,24 +291,94 @@ func (c *Cache) Add(key string, value Value) {
 		kv := ele.Value.(*entry)
 		// 更新缓存的总字节数 c.nbytes。如果替换了缓存中的值，需要调整字节数，增加新值的字节数减去旧值的字节数
 		c.nbytes += int64(value.Len()) - int64(kv.value.Len())
+		old := kv.value
 		// 将新的 value 赋值给 entry 结构体的 value 字段
 		kv.value = value
+		c.setExpire(kv, expire)
+		c.stats.overwrites.Add(1)
+		if c.OnEvictedWithReason != nil {
+			c.OnEvictedWithReason(key, old, EvictedReplaced)
+		}
 	} else {
 		// 使用 PushFront 方法将新的 entry（包含 key 和 value）添加到链表的前端
-		ele := c.ll.PushFront(&entry{
+		kv := &entry{
 			key:   key,
 			value: value,
-		})
+			index: -1,
+		}
+		ele := c.ll.PushFront(kv)
 		c.cache[key] = ele
-		c.nbytes += int64(len(key)) + int64(value.Len())
+		c.nbytes += c.entrySize(key, value)
+		c.setExpire(kv, expire)
+		c.stats.adds.Add(1)
 	}
-	// 如果设置了最大字节数 c.maxBytes 并且当前缓存的总字节数 c.nbytes 超过了这个限制，则删除最近最少使用的元素
-	for c.maxBytes != 0 && c.maxBytes < c.nbytes {
+	return nil
+}
+
+// evict 在缓存超过内存或条目数限制时，删除最近最少使用的元素
+func (c *Cache) evict() {
+	for c.overLimit() {
 		c.RemoveOldest()
 	}
 }
 
+// entrySize 返回一条记录计入 nbytes 的大小
+func (c *Cache) entrySize(key string, value Value) int64 {
+	return int64(len(key)) + int64(value.Len()) + c.overhead
+}
+
+// SetOverheadAccounting 开启或关闭开销统计
+// 默认只统计 len(key)+value.Len()，开启后每条记录额外计入 EntryOverhead 字节，使 maxBytes 更接近实际占用的内存
+// 已有记录的大小会被重新计算，超出 maxBytes 时立即淘汰
+func (c *Cache) SetOverheadAccounting(enabled bool) {
+	var overhead int64
+	if enabled {
+		overhead = EntryOverhead
+	}
+	c.nbytes += int64(c.ll.Len()) * (overhead - c.overhead)
+	c.overhead = overhead
+	c.evict()
+}
+
+// overLimit 判断缓存是否超出了内存或条目数限制
+func (c *Cache) overLimit() bool {
+	// 如果设置了最大字节数 c.maxBytes 并且当前缓存的总字节数 c.nbytes 超过了这个限制
+	if c.maxBytes != 0 && c.maxBytes < c.nbytes {
+		return true
+	}
+	return c.maxEntries != 0 && c.maxEntries < c.ll.Len()
+}
+
+// setExpire 更新记录的过期时间，并同步维护过期堆
+func (c *Cache) setExpire(kv *entry, expire time.Time) {
+	kv.expire = expire
+	switch {
+	case expire.IsZero() && kv.index >= 0:
+		heap.Remove(&c.expiries, kv.index)
+	case expire.IsZero():
+	case kv.index >= 0:
+		heap.Fix(&c.expiries, kv.index)
+	default:
+		heap.Push(&c.expiries, kv)
+	}
+}
+
 // Len 返回缓存中条目的数量
 func (c *Cache) Len() int {
 	return c.ll.Len()
 }
+
+// Bytes 返回缓存当前已使用的内存
+func (c *Cache) Bytes() int64 {
+	return c.nbytes
+}
+
+// MaxBytes 返回允许使用的最大内存，0 表示不限制
+func (c *Cache) MaxBytes() int64 {
+	return c.maxBytes
+}
+
+// MaxEntries 返回允许保存的最大条目数，0 表示不限制
+func (c *Cache) MaxEntries() int {
+	return c.maxEntries
+}
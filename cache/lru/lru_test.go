@@ -3,6 +3,7 @@ package lru
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 type String string
@@ -53,3 +54,306 @@ func TestOnEvicted(t *testing.T) {
 		t.Fatalf("Call OnEvicted failed, expect keys equals to %s", expect)
 	}
 }
+
+// fakeClock 是可以手动推进的时钟，用于测试过期逻辑
+type fakeClock struct {
+	t time.Time
+}
+
+func (f *fakeClock) now() time.Time {
+	return f.t
+}
+
+func (f *fakeClock) advance(d time.Duration) {
+	f.t = f.t.Add(d)
+}
+
+func TestAddWithTTL(t *testing.T) {
+	keys := make([]string, 0)
+	lru := New(int64(0), func(key string, value Value) {
+		keys = append(keys, key)
+	})
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	lru.now = clock.now
+
+	lru.AddWithTTL("key1", String("1"), time.Second)
+	lru.AddWithTTL("key2", String("2"), 3*time.Second)
+	lru.Add("key3", String("3"))
+
+	clock.advance(2 * time.Second)
+	if _, ok := lru.Get("key1"); ok {
+		t.Fatalf("expired key1 should be a miss")
+	}
+	if _, ok := lru.Get("key2"); !ok {
+		t.Fatalf("key2 should not expire yet")
+	}
+	if lru.Len() != 2 || lru.Bytes() != int64(len("key2")+len("key3")+2) {
+		t.Fatalf("expired key1 should be removed, len=%d bytes=%d", lru.Len(), lru.Bytes())
+	}
+
+	// 用 Add 覆盖会清除过期时间
+	lru.Add("key2", String("2"))
+	clock.advance(time.Hour)
+	if n := lru.RemoveExpired(); n != 0 {
+		t.Fatalf("expect no expired entries, removed %d", n)
+	}
+	if _, ok := lru.Get("key2"); !ok {
+		t.Fatalf("key2 overwritten by Add should never expire")
+	}
+
+	if !reflect.DeepEqual([]string{"key1"}, keys) {
+		t.Fatalf("Call OnEvicted failed, expect keys equals to %s, got %s", []string{"key1"}, keys)
+	}
+}
+
+func TestRemoveExpired(t *testing.T) {
+	lru := New(int64(0), nil)
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	lru.now = clock.now
+
+	for i := 1; i <= 5; i++ {
+		lru.AddWithTTL(string(rune('a'+i)), String("v"), time.Duration(i)*time.Second)
+	}
+	clock.advance(3 * time.Second)
+	if n := lru.RemoveExpired(); n != 3 || lru.Len() != 2 {
+		t.Fatalf("expect 3 expired entries removed, removed %d, len=%d", n, lru.Len())
+	}
+	// 刷新过期时间后不应被回收
+	lru.AddWithTTL("e", String("v"), time.Hour)
+	clock.advance(2 * time.Second)
+	if n := lru.RemoveExpired(); n != 1 || lru.Len() != 1 {
+		t.Fatalf("expect 1 expired entry removed, removed %d, len=%d", n, lru.Len())
+	}
+	if len(lru.expiries) != 1 {
+		t.Fatalf("expiry heap should stay in sync, got %d items", len(lru.expiries))
+	}
+}
+
+func TestOnEvictedWithReason(t *testing.T) {
+	got := make([]string, 0)
+	lru := New(int64(10), nil)
+	lru.OnEvictedWithReason = func(key string, value Value, reason EvictionReason) {
+		got = append(got, key+":"+reason.String())
+	}
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	lru.now = clock.now
+
+	lru.Add("k1", String("v1"))
+	lru.Add("k1", String("v2"))
+	lru.AddWithTTL("k2", String("v2"), time.Second)
+	lru.Add("k3", String("v3"))
+	clock.advance(time.Second)
+	lru.RemoveExpired()
+	lru.RemoveOldest()
+
+	expect := []string{"k1:replaced", "k1:capacity", "k2:expired", "k3:capacity"}
+	if !reflect.DeepEqual(expect, got) {
+		t.Fatalf("Call OnEvictedWithReason failed, expect %s, got %s", expect, got)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	reasons := make([]EvictionReason, 0)
+	lru := New(int64(0), nil)
+	lru.OnEvictedWithReason = func(key string, value Value, reason EvictionReason) {
+		reasons = append(reasons, reason)
+	}
+	lru.Add("key1", String("1234"))
+	lru.Add("key2", String("5678"))
+
+	if !lru.Remove("key1") || lru.Remove("key1") {
+		t.Fatalf("Remove key1 should succeed exactly once")
+	}
+	if lru.Contains("key1") || lru.Len() != 1 || lru.Bytes() != int64(len("key2")+4) {
+		t.Fatalf("Remove key1 failed, len=%d bytes=%d", lru.Len(), lru.Bytes())
+	}
+	if !reflect.DeepEqual([]EvictionReason{EvictedRemoved}, reasons) {
+		t.Fatalf("Remove should report EvictedRemoved, got %v", reasons)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	lru := New(int64(0), nil)
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	lru.now = clock.now
+	lru.Add("k1", String("1"))
+	lru.Add("k2", String("2"))
+	lru.AddWithTTL("k3", String("3"), time.Second)
+
+	if v, ok := lru.Peek("k1"); !ok || string(v.(String)) != "1" {
+		t.Fatalf("Peek k1 failed")
+	}
+	// Peek 不应改变新旧顺序
+	if keys := lru.Keys(); !reflect.DeepEqual([]string{"k3", "k2", "k1"}, keys) {
+		t.Fatalf("Peek should not promote k1, got keys %s", keys)
+	}
+	lru.Get("k1")
+	if keys := lru.Keys(); !reflect.DeepEqual([]string{"k1", "k3", "k2"}, keys) {
+		t.Fatalf("Get should promote k1, got keys %s", keys)
+	}
+
+	if k, v, ok := lru.Oldest(); !ok || k != "k2" || string(v.(String)) != "2" {
+		t.Fatalf("Oldest should be k2, got %s", k)
+	}
+
+	clock.advance(time.Second)
+	if _, ok := lru.Peek("k3"); ok || lru.Contains("k3") {
+		t.Fatalf("expired k3 should be a miss")
+	}
+	if keys := lru.Keys(); !reflect.DeepEqual([]string{"k1", "k2"}, keys) {
+		t.Fatalf("Keys should skip expired k3, got keys %s", keys)
+	}
+}
+
+func TestClear(t *testing.T) {
+	keys := make([]string, 0)
+	lru := New(int64(0), func(key string, value Value) {
+		keys = append(keys, key)
+	})
+	lru.Add("k1", String("1"))
+	lru.AddWithTTL("k2", String("2"), time.Hour)
+
+	lru.Clear(false)
+	if lru.Len() != 0 || lru.Bytes() != 0 || len(keys) != 0 {
+		t.Fatalf("Clear(false) failed, len=%d bytes=%d callbacks=%d", lru.Len(), lru.Bytes(), len(keys))
+	}
+
+	lru.Add("k1", String("1"))
+	lru.AddWithTTL("k2", String("2"), time.Hour)
+	lru.Clear(true)
+	if lru.Len() != 0 || lru.Bytes() != 0 || len(lru.expiries) != 0 {
+		t.Fatalf("Clear(true) failed, len=%d bytes=%d", lru.Len(), lru.Bytes())
+	}
+	if !reflect.DeepEqual([]string{"k1", "k2"}, keys) {
+		t.Fatalf("Clear(true) should evict from oldest, got %s", keys)
+	}
+}
+
+func TestMaxEntries(t *testing.T) {
+	keys := make([]string, 0)
+	lru := NewWithLimits(int64(0), 2, func(key string, value Value) {
+		keys = append(keys, key)
+	})
+	lru.Add("k1", String("1"))
+	lru.Add("k2", String("2"))
+	lru.Add("k3", String("3"))
+
+	if lru.Len() != 2 || lru.Contains("k1") {
+		t.Fatalf("max entries limit failed, len=%d", lru.Len())
+	}
+	if lru.MaxEntries() != 2 || lru.MaxBytes() != 0 {
+		t.Fatalf("limits accessors failed, maxEntries=%d maxBytes=%d", lru.MaxEntries(), lru.MaxBytes())
+	}
+	if !reflect.DeepEqual([]string{"k1"}, keys) {
+		t.Fatalf("Call OnEvicted failed, expect keys equals to %s, got %s", []string{"k1"}, keys)
+	}
+}
+
+func TestMaxEntriesAndBytes(t *testing.T) {
+	lru := NewWithLimits(int64(12), 3, nil)
+	// 条目数先达到上限
+	lru.Add("a", String("1"))
+	lru.Add("b", String("2"))
+	lru.Add("c", String("3"))
+	lru.Add("d", String("4"))
+	if !reflect.DeepEqual([]string{"d", "c", "b"}, lru.Keys()) {
+		t.Fatalf("entries limit should evict a, got keys %s", lru.Keys())
+	}
+	// 内存先达到上限
+	lru.Add("e", String("123456789"))
+	if !reflect.DeepEqual([]string{"e", "d"}, lru.Keys()) || lru.Bytes() != 12 {
+		t.Fatalf("bytes limit should evict until both hold, got keys %s bytes=%d", lru.Keys(), lru.Bytes())
+	}
+}
+
+func TestTryAdd(t *testing.T) {
+	keys := make([]string, 0)
+	lru := New(int64(10), func(key string, value Value) {
+		keys = append(keys, key)
+	})
+	lru.Add("k1", String("v1"))
+	lru.Add("k2", String("v2"))
+
+	// 比整个缓存大 1 字节的记录被拒绝，已有记录保持不变
+	if err := lru.TryAdd("k3", String("123456789")); err != ErrEntryTooLarge {
+		t.Fatalf("expect ErrEntryTooLarge, got %v", err)
+	}
+	if lru.Len() != 2 || lru.Bytes() != 8 || len(keys) != 0 {
+		t.Fatalf("oversize entry should not evict existing data, len=%d bytes=%d", lru.Len(), lru.Bytes())
+	}
+	// Add 对超大记录同样不会清空缓存
+	lru.Add("k3", String("123456789"))
+	if lru.Len() != 2 || lru.Contains("k3") {
+		t.Fatalf("Add should drop oversize entry, len=%d", lru.Len())
+	}
+
+	// 恰好等于整个缓存大小的记录可以写入，并淘汰其余记录
+	if err := lru.TryAdd("k3", String("12345678")); err != nil {
+		t.Fatalf("entry equals to maxBytes should be accepted, got %v", err)
+	}
+	if lru.Len() != 1 || lru.Bytes() != 10 {
+		t.Fatalf("expect only k3 left, len=%d bytes=%d", lru.Len(), lru.Bytes())
+	}
+	if !reflect.DeepEqual([]string{"k1", "k2"}, keys) {
+		t.Fatalf("Call OnEvicted failed, expect keys equals to %s, got %s", []string{"k1", "k2"}, keys)
+	}
+
+	// 用超大记录覆盖已有的键时，旧值被删除
+	if err := lru.TryAdd("k3", String("123456789")); err != ErrEntryTooLarge {
+		t.Fatalf("expect ErrEntryTooLarge, got %v", err)
+	}
+	if lru.Contains("k3") || lru.Len() != 0 || lru.Bytes() != 0 {
+		t.Fatalf("stale k3 should be removed, len=%d bytes=%d", lru.Len(), lru.Bytes())
+	}
+}
+
+func TestTryAddUnlimited(t *testing.T) {
+	lru := NewWithLimits(int64(0), 1, nil)
+	if err := lru.TryAdd("key", String(make([]byte, 1<<20))); err != nil {
+		t.Fatalf("cache without maxBytes should accept any entry, got %v", err)
+	}
+}
+
+func TestStats(t *testing.T) {
+	lru := NewWithLimits(int64(0), 2, nil)
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	lru.now = clock.now
+
+	lru.Add("k1", String("v1"))
+	lru.Add("k1", String("v11"))
+	lru.AddWithTTL("k2", String("v2"), time.Second)
+	lru.Get("k1")
+	lru.Get("k3")
+	lru.Add("k3", String("v3"))
+	lru.Remove("k1")
+	lru.AddWithTTL("k4", String("v4"), time.Second)
+	clock.advance(time.Second)
+	lru.Get("k4")
+
+	s := lru.Stats()
+	expect := Stats{
+		Hits:       1,
+		Misses:     2,
+		Adds:       4,
+		Overwrites: 1,
+		Evictions: map[EvictionReason]uint64{
+			EvictedCapacity: 1,
+			EvictedRemoved:  1,
+			EvictedExpired:  1,
+		},
+		Bytes:   int64(len("k3") + len("v3")),
+		Entries: 1,
+	}
+	if !reflect.DeepEqual(expect, s) {
+		t.Fatalf("expect stats %+v, got %+v", expect, s)
+	}
+	if r := s.HitRatio(); r < 0.33 || r > 0.34 {
+		t.Fatalf("expect hit ratio 1/3, got %f", r)
+	}
+
+	lru.ResetStats()
+	s = lru.Stats()
+	if s.Hits != 0 || s.Misses != 0 || s.Adds != 0 || s.Evictions[EvictedCapacity] != 0 || s.Entries != 1 {
+		t.Fatalf("ResetStats failed, got %+v", s)
+	}
+}
@@ -0,0 +1,107 @@
+package lru
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+// empty 不占用额外内存的值，用于单独测量每条记录的开销
+type empty struct{}
+
+func (*empty) Len() int {
+	return 0
+}
+
+func TestOverheadAccounting(t *testing.T) {
+	lru := New(int64(0), nil)
+	lru.Add("k1", String("v1"))
+	lru.SetOverheadAccounting(true)
+	if lru.Bytes() != 4+EntryOverhead {
+		t.Fatalf("expect %d bytes, got %d", 4+EntryOverhead, lru.Bytes())
+	}
+	lru.Add("k2", String("v2"))
+	lru.Remove("k1")
+	if lru.Bytes() != 4+EntryOverhead {
+		t.Fatalf("expect %d bytes, got %d", 4+EntryOverhead, lru.Bytes())
+	}
+	lru.SetOverheadAccounting(false)
+	if lru.Bytes() != 4 {
+		t.Fatalf("expect 4 bytes, got %d", lru.Bytes())
+	}
+}
+
+func TestOverheadAccountingEvict(t *testing.T) {
+	lru := New(int64(2*EntryOverhead), nil)
+	for i := 0; i < 4; i++ {
+		lru.Add("k"+strconv.Itoa(i), String("v"))
+	}
+	lru.SetOverheadAccounting(true)
+	if lru.Len() != 1 || lru.Bytes() > lru.MaxBytes() {
+		t.Fatalf("enabling overhead accounting should evict down to maxBytes, len=%d bytes=%d", lru.Len(), lru.Bytes())
+	}
+	if err := lru.TryAdd("k", String("v")); err != nil {
+		t.Fatal(err)
+	}
+	if err := lru.TryAdd("big", String(make([]byte, EntryOverhead))); err != ErrEntryTooLarge {
+		t.Fatalf("entry plus overhead exceeds maxBytes, expect ErrEntryTooLarge, got %v", err)
+	}
+}
+
+// heapGrowth 返回执行 fn 前后堆内存的增长
+func heapGrowth(fn func()) int64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	fn()
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	return int64(after.HeapAlloc) - int64(before.HeapAlloc)
+}
+
+// TestOverheadMemStats 开启开销统计后，nbytes 应与实际的堆内存增长接近
+func TestOverheadMemStats(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping memory measurement in short mode")
+	}
+	const n = 100000
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+	}
+	value := &empty{}
+
+	lru := New(int64(0), nil)
+	lru.SetOverheadAccounting(true)
+	growth := heapGrowth(func() {
+		for _, k := range keys {
+			lru.Add(k, value)
+		}
+	})
+	// 键本身的内存在测量之前就已分配，从 nbytes 中扣除
+	var keyBytes int64
+	for _, k := range keys {
+		keyBytes += int64(len(k))
+	}
+	reported := lru.Bytes() - keyBytes
+	ratio := float64(reported) / float64(growth)
+	t.Logf("reported %d bytes, heap grew %d bytes, ratio %.2f", reported, growth, ratio)
+	if ratio < 0.7 || ratio > 1.3 {
+		t.Fatalf("reported overhead deviates too much from heap growth, ratio %.2f", ratio)
+	}
+	runtime.KeepAlive(lru)
+}
+
+func BenchmarkAddOverheadAccounting(b *testing.B) {
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+	}
+	lru := New(int64(512*EntryOverhead), nil)
+	lru.SetOverheadAccounting(true)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lru.Add(keys[i%len(keys)], String("value"))
+	}
+}
@@ -0,0 +1,200 @@
+package lru
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// snapshotVersion 是快照格式的版本号，格式不兼容地变化时需要递增
+const snapshotVersion = 1
+
+// snapshotMagic 是快照文件开头的魔数
+var snapshotMagic = [4]byte{'L', 'R', 'U', 'S'}
+
+// ErrBadSnapshot 表示快照数据损坏、被截断或版本不受支持
+var ErrBadSnapshot = errors.New("lru: bad snapshot")
+
+// Codec 负责 Value 与字节之间的转换，用于保存和恢复快照
+type Codec interface {
+	Encode(value Value) ([]byte, error)
+	Decode(data []byte) (Value, error)
+}
+
+// 快照格式：
+//
+//	magic(4) | version(1) | count | entry... | crc32(4)
+//	entry: len(key) key | 过期时间的 UnixNano，0 表示永不过期 | len(value) value
+//
+// 其中 count、长度和过期时间都使用 uvarint 编码，条目按从新到旧的顺序排列，
+// crc32 是前面所有字节的 IEEE 校验和，使用大端序
+
+// Save 将缓存中未过期的记录按从新到旧的顺序写入 w
+func (c *Cache) Save(w io.Writer, codec Codec) error {
+	var buf bytes.Buffer
+	buf.Write(snapshotMagic[:])
+	buf.WriteByte(snapshotVersion)
+
+	keys := c.Keys()
+	buf.Write(binary.AppendUvarint(nil, uint64(len(keys))))
+	for _, key := range keys {
+		kv := c.cache[key].Value.(*entry)
+		data, err := codec.Encode(kv.value)
+		if err != nil {
+			return fmt.Errorf("lru: encode %s: %v", key, err)
+		}
+		var expire int64
+		if !kv.expire.IsZero() {
+			expire = kv.expire.UnixNano()
+		}
+		b := binary.AppendUvarint(nil, uint64(len(key)))
+		b = append(b, key...)
+		b = binary.AppendUvarint(b, uint64(expire))
+		b = binary.AppendUvarint(b, uint64(len(data)))
+		buf.Write(b)
+		buf.Write(data)
+	}
+	buf.Write(binary.BigEndian.AppendUint32(nil, crc32.ChecksumIEEE(buf.Bytes())))
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Load 清空缓存，并从 r 中恢复 Save 保存的记录，恢复后记录的新旧顺序与保存时相同
+// 从最新的记录开始恢复，直到超出 maxBytes 或 maxEntries 为止，已过期的记录会被跳过
+// 数据校验失败时返回 ErrBadSnapshot，缓存保持不变
+func (c *Cache) Load(r io.Reader, codec Codec) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	entries, err := decodeSnapshot(data, codec)
+	if err != nil {
+		return err
+	}
+
+	c.Clear(false)
+	for _, kv := range entries {
+		if _, ok := c.cache[kv.key]; ok || c.expired(kv) {
+			continue
+		}
+		size := c.entrySize(kv.key, kv.value)
+		if c.maxBytes != 0 && c.nbytes+size > c.maxBytes {
+			break
+		}
+		if c.maxEntries != 0 && c.ll.Len() >= c.maxEntries {
+			break
+		}
+		expire := kv.expire
+		kv.expire = time.Time{}
+		// 按从新到旧的顺序依次追加到链表尾部
+		c.cache[kv.key] = c.ll.PushBack(kv)
+		c.nbytes += size
+		c.setExpire(kv, expire)
+	}
+	return nil
+}
+
+// decodeSnapshot 校验并解析快照数据
+func decodeSnapshot(data []byte, codec Codec) ([]*entry, error) {
+	if len(data) < len(snapshotMagic)+1+4 {
+		return nil, ErrBadSnapshot
+	}
+	body, sum := data[:len(data)-4], binary.BigEndian.Uint32(data[len(data)-4:])
+	if crc32.ChecksumIEEE(body) != sum {
+		return nil, ErrBadSnapshot
+	}
+	if !bytes.Equal(body[:len(snapshotMagic)], snapshotMagic[:]) || body[len(snapshotMagic)] != snapshotVersion {
+		return nil, ErrBadSnapshot
+	}
+	body = body[len(snapshotMagic)+1:]
+
+	uvarint := func() (uint64, bool) {
+		v, n := binary.Uvarint(body)
+		if n <= 0 {
+			return 0, false
+		}
+		body = body[n:]
+		return v, true
+	}
+	next := func() ([]byte, bool) {
+		n, ok := uvarint()
+		if !ok || uint64(len(body)) < n {
+			return nil, false
+		}
+		b := body[:n]
+		body = body[n:]
+		return b, true
+	}
+
+	count, ok := uvarint()
+	if !ok {
+		return nil, ErrBadSnapshot
+	}
+	entries := make([]*entry, 0)
+	for i := uint64(0); i < count; i++ {
+		key, ok := next()
+		if !ok {
+			return nil, ErrBadSnapshot
+		}
+		expire, ok := uvarint()
+		if !ok {
+			return nil, ErrBadSnapshot
+		}
+		data, ok := next()
+		if !ok {
+			return nil, ErrBadSnapshot
+		}
+		value, err := codec.Decode(data)
+		if err != nil {
+			return nil, fmt.Errorf("lru: decode %s: %v", key, err)
+		}
+		kv := &entry{key: string(key), value: value, index: -1}
+		if expire != 0 {
+			kv.expire = time.Unix(0, int64(expire))
+		}
+		entries = append(entries, kv)
+	}
+	if len(body) != 0 {
+		return nil, ErrBadSnapshot
+	}
+	return entries, nil
+}
+
+// SaveFile 将快照保存到文件，先写入同目录下的临时文件再重命名，避免留下写了一半的文件
+func (c *Cache) SaveFile(path string, codec Codec) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := c.Save(f, codec); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// LoadFile 从文件中恢复快照
+func (c *Cache) LoadFile(path string, codec Codec) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.Load(f, codec)
+}
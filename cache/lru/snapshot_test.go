@@ -0,0 +1,124 @@
+package lru
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// stringCodec 在快照中以原始字节保存 String
+type stringCodec struct{}
+
+func (stringCodec) Encode(value Value) ([]byte, error) {
+	return []byte(value.(String)), nil
+}
+
+func (stringCodec) Decode(data []byte) (Value, error) {
+	return String(data), nil
+}
+
+func TestSnapshot(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(100, 0)}
+	src := New(int64(0), nil)
+	src.now = clock.now
+	src.Add("k1", String("v1"))
+	src.AddWithTTL("k2", String("v2"), time.Minute)
+	src.Add("k3", String("v3"))
+	src.AddWithTTL("k4", String("v4"), time.Second)
+	src.Get("k1")
+
+	var buf bytes.Buffer
+	if err := src.Save(&buf, stringCodec{}); err != nil {
+		t.Fatal(err)
+	}
+
+	clock.advance(2 * time.Second)
+	dst := New(int64(0), nil)
+	dst.now = clock.now
+	dst.Add("old", String("value"))
+	if err := dst.Load(&buf, stringCodec{}); err != nil {
+		t.Fatal(err)
+	}
+	// k4 已过期，其余记录保持原来的新旧顺序
+	if keys := dst.Keys(); !reflect.DeepEqual([]string{"k1", "k3", "k2"}, keys) {
+		t.Fatalf("expect keys [k1 k3 k2], got %s", keys)
+	}
+	if dst.Bytes() != 12 || dst.Len() != 3 {
+		t.Fatalf("unexpected usage, len=%d bytes=%d", dst.Len(), dst.Bytes())
+	}
+	// 过期时间同样被恢复
+	clock.advance(time.Minute)
+	if _, ok := dst.Get("k2"); ok {
+		t.Fatalf("k2 should expire after restore")
+	}
+}
+
+func TestSnapshotMaxBytes(t *testing.T) {
+	src := New(int64(0), nil)
+	for _, k := range []string{"k1", "k2", "k3", "k4"} {
+		src.Add(k, String("v"))
+	}
+	var buf bytes.Buffer
+	if err := src.Save(&buf, stringCodec{}); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := New(int64(7), nil)
+	if err := dst.Load(&buf, stringCodec{}); err != nil {
+		t.Fatal(err)
+	}
+	if keys := dst.Keys(); !reflect.DeepEqual([]string{"k4", "k3"}, keys) {
+		t.Fatalf("expect the newest entries [k4 k3] to be kept, got %s", keys)
+	}
+}
+
+func TestSnapshotCorrupted(t *testing.T) {
+	src := New(int64(0), nil)
+	src.Add("k1", String("v1"))
+	src.Add("k2", String("v2"))
+	var buf bytes.Buffer
+	if err := src.Save(&buf, stringCodec{}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	dst := New(int64(0), nil)
+	dst.Add("old", String("value"))
+	for i := 0; i < len(data); i++ {
+		if err := dst.Load(bytes.NewReader(data[:i]), stringCodec{}); err != ErrBadSnapshot {
+			t.Fatalf("load %d of %d bytes: expect ErrBadSnapshot, got %v", i, len(data), err)
+		}
+	}
+	bad := append([]byte(nil), data...)
+	bad[len(bad)/2] ^= 0xff
+	if err := dst.Load(bytes.NewReader(bad), stringCodec{}); err != ErrBadSnapshot {
+		t.Fatalf("expect ErrBadSnapshot for flipped byte, got %v", err)
+	}
+	if !reflect.DeepEqual([]string{"old"}, dst.Keys()) {
+		t.Fatalf("cache should stay unchanged after a failed load, got %s", dst.Keys())
+	}
+}
+
+func TestSnapshotFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.snapshot")
+	src := New(int64(0), nil)
+	src.Add("k1", String("v1"))
+	src.Add("k2", String("v2"))
+	if err := src.SaveFile(path, stringCodec{}); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := New(int64(0), nil)
+	if err := dst.LoadFile(path, stringCodec{}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(src.Keys(), dst.Keys()) {
+		t.Fatalf("expect keys %s, got %s", src.Keys(), dst.Keys())
+	}
+	matches, _ := filepath.Glob(path + ".tmp*")
+	if len(matches) != 0 {
+		t.Fatalf("temporary files should be removed, got %s", matches)
+	}
+}
@@ -0,0 +1,64 @@
+package lru
+
+import "sync/atomic"
+
+// numReasons 是 EvictionReason 的取值个数
+const numReasons = int(EvictedExpired) + 1
+
+// Stats 是缓存运行情况的快照
+type Stats struct {
+	Hits       uint64                    // Get 命中的次数
+	Misses     uint64                    // Get 未命中的次数，包括命中已过期的记录
+	Adds       uint64                    // 写入新键的次数
+	Overwrites uint64                    // 覆盖已有键的次数，即以 EvictedReplaced 离开缓存的旧值个数
+	Evictions  map[EvictionReason]uint64 // 按原因统计的移除次数，不包括 EvictedReplaced
+	Bytes      int64                     // 当前已使用的内存
+	Entries    int                       // 当前条目的数量
+}
+
+// HitRatio 返回命中率，没有任何 Get 时返回 0
+func (s Stats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// counters 保存统计用的计数器，使用原子操作，维护开销很低
+type counters struct {
+	hits       atomic.Uint64
+	misses     atomic.Uint64
+	adds       atomic.Uint64
+	overwrites atomic.Uint64
+	evictions  [numReasons]atomic.Uint64
+}
+
+// Stats 返回当前的统计信息
+func (c *Cache) Stats() Stats {
+	s := Stats{
+		Hits:       c.stats.hits.Load(),
+		Misses:     c.stats.misses.Load(),
+		Adds:       c.stats.adds.Load(),
+		Overwrites: c.stats.overwrites.Load(),
+		Evictions:  make(map[EvictionReason]uint64),
+		Bytes:      c.nbytes,
+		Entries:    c.ll.Len(),
+	}
+	for i := range c.stats.evictions {
+		if reason := EvictionReason(i); reason != EvictedReplaced {
+			s.Evictions[reason] = c.stats.evictions[i].Load()
+		}
+	}
+	return s
+}
+
+// ResetStats 将所有计数器清零，当前内存和条目数不受影响
+func (c *Cache) ResetStats() {
+	c.stats.hits.Store(0)
+	c.stats.misses.Store(0)
+	c.stats.adds.Store(0)
+	c.stats.overwrites.Store(0)
+	for i := range c.stats.evictions {
+		c.stats.evictions[i].Store(0)
+	}
+}
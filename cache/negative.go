@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"cache/lru"
+	"sync"
+	"time"
+)
+
+// errorValue 是保存在负缓存中的加载错误
+type errorValue struct {
+	err error
+}
+
+func (v errorValue) Len() int {
+	return len(v.err.Error())
+}
+
+// negativeCache 缓存加载失败（例如数据源中不存在该键）的结果，避免相同的请求反复穿透到数据源
+// 它与保存正常值的 mainCache 相互独立，有自己的过期时间和容量，也不影响 mainCache 的命中统计
+type negativeCache struct {
+	mu  sync.Mutex
+	lru *lru.Cache
+	ttl time.Duration
+}
+
+func (c *negativeCache) get(key string) (value errorValue, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if v, ok := c.lru.Get(key); ok {
+		return v.(errorValue), true
+	}
+	return
+}
+
+func (c *negativeCache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Remove(key)
+}
+
+func (c *negativeCache) add(key string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.AddWithTTL(key, errorValue{err: err}, c.ttl)
+}
+
+// SetNegativeCache 开启负缓存，加载失败的结果会在 ttl 内直接返回相同的错误而不再调用 Getter
+// maxEntries 是负缓存最多保存的键数，0 表示不限制；需要在使用 Group 之前调用
+// 负缓存的记录必须会过期，ttl 小于等于 0 时不开启负缓存（已开启的会被关闭），而不是永久缓存错误
+func (g *Group) SetNegativeCache(ttl time.Duration, maxEntries int) {
+	if ttl <= 0 {
+		g.negCache = nil
+		return
+	}
+	g.negCache = &negativeCache{
+		lru: lru.NewWithLimits(0, maxEntries, nil),
+		ttl: ttl,
+	}
+}
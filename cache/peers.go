@@ -0,0 +1,22 @@
+package cache
+
+// PeerPicker 根据键选出负责该键的远程节点
+type PeerPicker interface {
+	// PickPeer 返回键所属的远程节点，键属于当前节点或没有可用节点时 ok 为 false
+	PickPeer(key string) (peer PeerGetter, ok bool)
+}
+
+// PeerGetter 从远程节点获取指定 group 中键对应的值
+type PeerGetter interface {
+	Get(group string, key string) ([]byte, error)
+}
+
+// PeerLister 是 PeerPicker 可以选择实现的接口，用于列出除当前节点以外的所有远程节点
+type PeerLister interface {
+	ListPeers() []PeerGetter
+}
+
+// PeerDeleter 是 PeerGetter 可以选择实现的接口，用于通知远程节点删除 group 中的键
+type PeerDeleter interface {
+	Delete(group string, key string) error
+}
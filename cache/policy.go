@@ -0,0 +1,15 @@
+package cache
+
+import "cache/lru"
+
+// Policy 是淘汰策略需要实现的接口，Cache 通过它存取数据，而不关心具体的淘汰算法
+// lru.Cache、lfu.Cache、fifo.Cache、twoq.Cache 和 tinylfu.Cache 都实现了该接口，淘汰时各自调用构造时传入的 OnEvicted
+// Policy 的实现不需要是并发安全的，Cache 会在调用前加锁
+type Policy interface {
+	Get(key string) (value lru.Value, ok bool) // 查找键对应的值
+	Add(key string, value lru.Value)           // 新增或更新一条记录，超出容量时按策略淘汰
+	Remove(key string) bool                    // 删除键对应的记录，返回记录是否存在
+	RemoveOldest()                             // 按策略淘汰一条记录
+	Len() int                                  // 返回缓存中条目的数量
+	Bytes() int64                              // 返回缓存当前已使用的内存
+}
@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"cache/fifo"
+	"cache/lfu"
+	"cache/lru"
+	"cache/tinylfu"
+	"cache/twoq"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// policies 列出所有需要通过一致性测试的淘汰策略
+var policies = []struct {
+	name string
+	new  func(maxBytes int64, onEvicted func(string, lru.Value)) Policy
+}{
+	{"lru", func(maxBytes int64, onEvicted func(string, lru.Value)) Policy {
+		return lru.New(maxBytes, onEvicted)
+	}},
+	{"lfu", func(maxBytes int64, onEvicted func(string, lru.Value)) Policy {
+		return lfu.New(maxBytes, onEvicted)
+	}},
+	{"fifo", func(maxBytes int64, onEvicted func(string, lru.Value)) Policy {
+		return fifo.New(maxBytes, onEvicted)
+	}},
+	{"twoq", func(maxBytes int64, onEvicted func(string, lru.Value)) Policy {
+		return twoq.New(maxBytes, onEvicted)
+	}},
+	{"tinylfu", func(maxBytes int64, onEvicted func(string, lru.Value)) Policy {
+		return tinylfu.New(maxBytes, 1000, onEvicted)
+	}},
+}
+
+// forEachPolicy 对每种淘汰策略运行同一个测试
+func forEachPolicy(t *testing.T, test func(t *testing.T, newPolicy func(int64, func(string, lru.Value)) Policy)) {
+	for _, p := range policies {
+		t.Run(p.name, func(t *testing.T) {
+			test(t, p.new)
+		})
+	}
+}
+
+func TestPolicyGet(t *testing.T) {
+	forEachPolicy(t, func(t *testing.T, newPolicy func(int64, func(string, lru.Value)) Policy) {
+		p := newPolicy(int64(0), nil)
+		p.Add("key1", String("1234"))
+		if v, ok := p.Get("key1"); !ok || string(v.(String)) != "1234" {
+			t.Fatalf("cache hit key1=1234 failed")
+		}
+		if _, ok := p.Get("key2"); ok {
+			t.Fatalf("cache miss key2 failed")
+		}
+		p.Add("key1", String("12"))
+		if v, ok := p.Get("key1"); !ok || string(v.(String)) != "12" || p.Len() != 1 || p.Bytes() != 6 {
+			t.Fatalf("overwrite key1 failed, len=%d bytes=%d", p.Len(), p.Bytes())
+		}
+	})
+}
+
+func TestPolicyRemove(t *testing.T) {
+	forEachPolicy(t, func(t *testing.T, newPolicy func(int64, func(string, lru.Value)) Policy) {
+		evicted := make([]string, 0)
+		p := newPolicy(int64(0), func(key string, value lru.Value) {
+			evicted = append(evicted, key)
+		})
+		p.Add("k1", String("v1"))
+		p.Add("k2", String("v2"))
+		if !p.Remove("k1") || p.Remove("k1") {
+			t.Fatalf("Remove k1 should succeed exactly once")
+		}
+		if _, ok := p.Get("k1"); ok || p.Len() != 1 || p.Bytes() != 4 {
+			t.Fatalf("Remove k1 failed, len=%d bytes=%d", p.Len(), p.Bytes())
+		}
+		p.RemoveOldest()
+		p.RemoveOldest()
+		if p.Len() != 0 || p.Bytes() != 0 || len(evicted) != 2 {
+			t.Fatalf("RemoveOldest failed, len=%d bytes=%d evicted=%s", p.Len(), p.Bytes(), evicted)
+		}
+	})
+}
+
+// TestPolicyMaxBytes 随机读写之后，内存不超过上限，且 Len、Bytes 与淘汰回调记录的结果一致
+func TestPolicyMaxBytes(t *testing.T) {
+	forEachPolicy(t, func(t *testing.T, newPolicy func(int64, func(string, lru.Value)) Policy) {
+		live := make(map[string]int64)
+		p := newPolicy(int64(256), func(key string, value lru.Value) {
+			if _, ok := live[key]; !ok {
+				t.Fatalf("OnEvicted called with unknown key %s", key)
+			}
+			delete(live, key)
+		})
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < 5000; i++ {
+			key := "key" + strconv.Itoa(r.Intn(100))
+			if r.Intn(3) == 0 {
+				if v, ok := p.Get(key); ok && int64(len(key)+v.Len()) != live[key] {
+					t.Fatalf("Get %s returned a stale value", key)
+				}
+				continue
+			}
+			value := String(make([]byte, r.Intn(20)))
+			live[key] = int64(len(key) + value.Len())
+			p.Add(key, value)
+			if p.Bytes() > 256 {
+				t.Fatalf("cache uses %d bytes, exceeds maxBytes 256", p.Bytes())
+			}
+		}
+		var nbytes int64
+		for _, n := range live {
+			nbytes += n
+		}
+		if p.Len() != len(live) || p.Bytes() != nbytes {
+			t.Fatalf("expect len=%d bytes=%d, got len=%d bytes=%d", len(live), nbytes, p.Len(), p.Bytes())
+		}
+	})
+}
+
+func TestNewWithPolicy(t *testing.T) {
+	forEachPolicy(t, func(t *testing.T, newPolicy func(int64, func(string, lru.Value)) Policy) {
+		c := NewWithPolicy(newPolicy(int64(0), nil))
+		c.Add("key1", String("1234"))
+		if v, ok := c.Get("key1"); !ok || string(v.(String)) != "1234" || c.Len() != 1 {
+			t.Fatalf("cache hit key1=1234 failed")
+		}
+	})
+}
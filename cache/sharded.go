@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"cache/lru"
+	"hash/fnv"
+)
+
+// ShardedCache 将键按哈希值分散到多个互相独立的 Cache 分片中，每个分片持有自己的锁
+// 不同分片上的操作可以并行执行，从而降低高并发下单把锁的争用
+type ShardedCache struct {
+	shards []*Cache
+}
+
+// NewSharded 创建一个包含 shards 个分片的缓存，maxBytes 平均分配给各个分片
+// maxBytes 为 0 表示不限制内存，shards 小于 1 时按 1 处理
+func NewSharded(shards int, maxBytes int64, onEvicted func(string, lru.Value)) *ShardedCache {
+	if shards < 1 {
+		shards = 1
+	}
+	shardBytes := maxBytes / int64(shards)
+	// 总预算不足以平分时，每个分片至少保留 1 字节，避免被当作不限制内存
+	if maxBytes > 0 && shardBytes == 0 {
+		shardBytes = 1
+	}
+	c := &ShardedCache{shards: make([]*Cache, shards)}
+	for i := range c.shards {
+		c.shards[i] = New(shardBytes, onEvicted)
+	}
+	return c
+}
+
+// shard 根据键的 FNV-1a 哈希值选出对应的分片
+func (c *ShardedCache) shard(key string) *Cache {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return c.shards[h.Sum32()%uint32(len(c.shards))]
+}
+
+// Get 在键所属的分片中查找对应的值
+func (c *ShardedCache) Get(key string) (value lru.Value, ok bool) {
+	return c.shard(key).Get(key)
+}
+
+// Add 将记录写入键所属的分片，淘汰只发生在该分片内部
+func (c *ShardedCache) Add(key string, value lru.Value) {
+	c.shard(key).Add(key, value)
+}
+
+// Len 返回所有分片中条目数量之和
+func (c *ShardedCache) Len() int {
+	n := 0
+	for _, s := range c.shards {
+		n += s.Len()
+	}
+	return n
+}
+
+// Bytes 返回所有分片已使用内存之和
+func (c *ShardedCache) Bytes() int64 {
+	var n int64
+	for _, s := range c.shards {
+		n += s.Bytes()
+	}
+	return n
+}
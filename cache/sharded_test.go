@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+func TestShardedCache(t *testing.T) {
+	c := NewSharded(8, int64(0), nil)
+	for i := 0; i < 100; i++ {
+		c.Add("key"+strconv.Itoa(i), String("v"))
+	}
+	if c.Len() != 100 {
+		t.Fatalf("expect 100 entries, got %d", c.Len())
+	}
+	// 每条记录占用 len("keyN") + 1 字节
+	var expect int64
+	for i := 0; i < 100; i++ {
+		expect += int64(len("key"+strconv.Itoa(i))) + 1
+	}
+	if c.Bytes() != expect {
+		t.Fatalf("expect %d bytes, got %d", expect, c.Bytes())
+	}
+	if v, ok := c.Get("key42"); !ok || string(v.(String)) != "v" {
+		t.Fatalf("cache hit key42 failed")
+	}
+	if _, ok := c.Get("missing"); ok {
+		t.Fatalf("cache miss missing failed")
+	}
+}
+
+func TestShardedCacheMaxBytes(t *testing.T) {
+	c := NewSharded(4, int64(400), nil)
+	for i := 0; i < 1000; i++ {
+		c.Add("key"+strconv.Itoa(i), String("0123456789"))
+	}
+	if c.Bytes() > 400 {
+		t.Fatalf("sharded cache uses %d bytes, exceeds the 400 bytes budget", c.Bytes())
+	}
+	for _, s := range c.shards {
+		if s.Bytes() > 100 {
+			t.Fatalf("shard uses %d bytes, exceeds its 100 bytes budget", s.Bytes())
+		}
+	}
+}
+
+const benchKeys = 4096
+
+var benchKeyNames = func() []string {
+	keys := make([]string, benchKeys)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+	}
+	return keys
+}()
+
+// benchmarkParallel 以 3:1 的读写比例并发访问缓存
+func benchmarkParallel(b *testing.B, get func(string), add func(string)) {
+	for _, k := range benchKeyNames {
+		add(k)
+	}
+	var seq uint64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := atomic.AddUint64(&seq, 1) * 7919
+		for pb.Next() {
+			k := benchKeyNames[i%benchKeys]
+			if i%4 == 0 {
+				add(k)
+			} else {
+				get(k)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkCacheParallel(b *testing.B) {
+	c := New(int64(0), nil)
+	benchmarkParallel(b,
+		func(k string) { c.Get(k) },
+		func(k string) { c.Add(k, String("value")) })
+}
+
+func BenchmarkShardedCacheParallel(b *testing.B) {
+	c := NewSharded(32, int64(0), nil)
+	benchmarkParallel(b,
+		func(k string) { c.Get(k) },
+		func(k string) { c.Add(k, String("value")) })
+}
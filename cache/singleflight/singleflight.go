@@ -0,0 +1,63 @@
+package singleflight
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrPanicked 是 fn 发生 panic 时，等待同一个请求的其他调用得到的错误
+var ErrPanicked = errors.New("singleflight: fn panicked")
+
+// call 代表正在进行中或已经结束的一次请求
+type call struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+}
+
+// Group 管理不同键的请求，同一个键的并发请求只会执行一次
+type Group struct {
+	mu sync.Mutex       // 保护 m
+	m  map[string]*call // 键是请求的 key，值是进行中的请求，延迟初始化
+}
+
+// Do 执行 fn 并返回结果，同一时刻针对相同 key 的多次调用只有第一次会真正执行 fn，
+// 其余调用等待它结束并共享同一个结果和错误
+func (g *Group) Do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.m == nil {
+		g.m = make(map[string]*call)
+	}
+	// 已经有相同 key 的请求在进行中，等待它返回
+	if c, ok := g.m[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.val, c.err
+	}
+	c := new(call)
+	c.wg.Add(1)
+	g.m[key] = c
+	g.mu.Unlock()
+
+	g.doCall(c, key, fn)
+	return c.val, c.err
+}
+
+// doCall 执行 fn，即使 fn 发生 panic 也会唤醒等待的调用并删除 key，panic 会继续向上传递给当前调用方
+func (g *Group) doCall(c *call, key string, fn func() (interface{}, error)) {
+	normalReturn := false
+	defer func() {
+		if !normalReturn {
+			// 等待同一个请求的其他调用拿到错误，而不是 nil 的结果
+			c.val, c.err = nil, ErrPanicked
+		}
+		c.wg.Done()
+
+		// 请求结束后删除，之后的调用会重新执行 fn，避免一直返回旧的结果
+		g.mu.Lock()
+		delete(g.m, key)
+		g.mu.Unlock()
+	}()
+	c.val, c.err = fn()
+	normalReturn = true
+}
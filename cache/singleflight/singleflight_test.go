@@ -0,0 +1,98 @@
+package singleflight
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDo(t *testing.T) {
+	var g Group
+	v, err := g.Do("key", func() (interface{}, error) {
+		return "bar", nil
+	})
+	if v != "bar" || err != nil {
+		t.Fatalf("Do v = %v, error = %v", v, err)
+	}
+}
+
+func TestDoErr(t *testing.T) {
+	var g Group
+	someErr := errors.New("some error")
+	v, err := g.Do("key", func() (interface{}, error) {
+		return nil, someErr
+	})
+	if err != someErr || v != nil {
+		t.Fatalf("Do v = %v, error = %v", v, err)
+	}
+}
+
+func TestDoDupSuppress(t *testing.T) {
+	var g Group
+	var calls int32
+	release := make(chan struct{})
+	fn := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "bar", nil
+	}
+
+	const n = 10
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := g.Do("key", fn)
+			if v != "bar" || err != nil {
+				t.Errorf("Do v = %v, error = %v", v, err)
+			}
+		}()
+	}
+	// 等待所有 goroutine 进入 Do 后再让 fn 返回
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("number of calls = %d; want 1", got)
+	}
+}
+
+// TestDoPanic fn 发生 panic 后，等待中的调用得到错误，之后对同一个 key 的调用仍能正常执行
+func TestDoPanic(t *testing.T) {
+	var g Group
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("panic should propagate to the caller")
+			}
+			close(done)
+		}()
+		g.Do("key", func() (interface{}, error) {
+			<-release
+			panic("boom")
+		})
+	}()
+	waiter := make(chan error)
+	go func() {
+		// 等待第一个调用进入 fn
+		time.Sleep(50 * time.Millisecond)
+		_, err := g.Do("key", func() (interface{}, error) { return "bar", nil })
+		waiter <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	<-done
+	if err := <-waiter; err != ErrPanicked {
+		t.Fatalf("waiting call should get ErrPanicked, got %v", err)
+	}
+
+	v, err := g.Do("key", func() (interface{}, error) { return "bar", nil })
+	if v != "bar" || err != nil {
+		t.Fatalf("Do after panic v = %v, error = %v", v, err)
+	}
+}
@@ -0,0 +1,107 @@
+package tinylfu
+
+import "hash/fnv"
+
+const (
+	sketchDepth = 4  // count-min sketch 的行数
+	maxCounter  = 15 // 单个计数器的上限，对应 TinyLFU 论文中的 4 位计数器
+)
+
+// sketch 是带门卫（doorkeeper）的 count-min sketch，用于估计键最近的访问频率
+// 第一次出现的键只记录在门卫布隆过滤器中，再次出现才写入计数器，避免一次性的键污染计数器
+// 写入次数达到 sampleSize 后，所有计数器减半并清空门卫，使旧的访问记录逐渐失效
+type sketch struct {
+	rows       [sketchDepth][]uint8
+	doorkeeper []uint64 // 门卫布隆过滤器的位图
+	mask       uint64   // 计数器下标掩码，行宽是 2 的幂
+	additions  int      // 自上次衰减以来的写入次数
+	sampleSize int      // 触发衰减的写入次数
+}
+
+// newSketch 创建能够跟踪大约 counters 个不同键的频率估计器
+func newSketch(counters int) *sketch {
+	width := 16
+	for width < counters {
+		width <<= 1
+	}
+	s := &sketch{
+		doorkeeper: make([]uint64, width/64+1),
+		mask:       uint64(width - 1),
+		sampleSize: 10 * width,
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// index 使用双重哈希为第 i 行计算下标
+func (s *sketch) index(h uint64, i int) uint64 {
+	return (h + uint64(i)*(h>>32|1)) & s.mask
+}
+
+// doorkeeperBits 返回键在门卫布隆过滤器中对应的两个位
+func (s *sketch) doorkeeperBits(h uint64) (uint64, uint64) {
+	n := uint64(len(s.doorkeeper) * 64)
+	return h % n, (h >> 32) % n
+}
+
+func (s *sketch) inDoorkeeper(h uint64) bool {
+	a, b := s.doorkeeperBits(h)
+	return s.doorkeeper[a/64]&(1<<(a%64)) != 0 && s.doorkeeper[b/64]&(1<<(b%64)) != 0
+}
+
+// Increment 记录一次访问
+func (s *sketch) Increment(key string) {
+	h := hashKey(key)
+	if !s.inDoorkeeper(h) {
+		a, b := s.doorkeeperBits(h)
+		s.doorkeeper[a/64] |= 1 << (a % 64)
+		s.doorkeeper[b/64] |= 1 << (b % 64)
+	} else {
+		for i := range s.rows {
+			if c := &s.rows[i][s.index(h, i)]; *c < maxCounter {
+				*c++
+			}
+		}
+	}
+	s.additions++
+	if s.additions >= s.sampleSize {
+		s.reset()
+	}
+}
+
+// Estimate 返回键的估计访问次数，取各行计数器的最小值，再加上门卫中的一次
+func (s *sketch) Estimate(key string) int {
+	h := hashKey(key)
+	min := uint8(maxCounter)
+	for i := range s.rows {
+		if c := s.rows[i][s.index(h, i)]; c < min {
+			min = c
+		}
+	}
+	n := int(min)
+	if s.inDoorkeeper(h) {
+		n++
+	}
+	return n
+}
+
+// reset 将所有计数器减半并清空门卫，实现频率的老化
+func (s *sketch) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	for i := range s.doorkeeper {
+		s.doorkeeper[i] = 0
+	}
+	s.additions /= 2
+}
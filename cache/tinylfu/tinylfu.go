@@ -0,0 +1,120 @@
+package tinylfu
+
+import "cache/lru"
+
+// windowRatio 窗口缓存占总内存的比例为 1/windowRatio
+const windowRatio = 100
+
+// Cache 是带有 W-TinyLFU 准入策略的缓存
+// 新记录先进入一个很小的窗口 LRU，从窗口淘汰的记录作为候选者，只有当它的估计访问频率
+// 高于主 LRU 中即将被淘汰的记录时才会进入主缓存，从而避免只访问一次的键冲刷热点数据
+type Cache struct {
+	window    *lru.Cache                    // 窗口缓存，接收所有新写入的记录
+	main      *lru.Cache                    // 主缓存，只接收通过准入检查的记录
+	sketch    *sketch                       // 访问频率估计器
+	OnEvicted func(key string, value Value) // 某条记录被移除（包括未通过准入检查）时的回调函数，可以为 nil
+}
+
+// Value 与 lru.Value 相同，使不同淘汰策略的缓存可以互相替换
+type Value = lru.Value
+
+// New 是Cache的构造方法，counters 是频率估计器跟踪的键数量，建议设置为缓存条目数的 10 倍左右
+func New(maxBytes int64, counters int, onEvicted func(string, Value)) *Cache {
+	windowBytes := maxBytes / windowRatio
+	if maxBytes > 0 && windowBytes == 0 {
+		windowBytes = 1
+	}
+	c := &Cache{
+		window:    lru.New(windowBytes, nil),
+		main:      lru.New(maxBytes-windowBytes, nil),
+		sketch:    newSketch(counters),
+		OnEvicted: onEvicted,
+	}
+	c.window.OnEvictedWithReason = c.onWindowEvicted
+	c.main.OnEvicted = c.evicted
+	return c
+}
+
+func (c *Cache) evicted(key string, value Value) {
+	if c.OnEvicted != nil {
+		c.OnEvicted(key, value)
+	}
+}
+
+// onWindowEvicted 处理窗口缓存移除的记录，因容量被淘汰的记录需要经过准入检查
+func (c *Cache) onWindowEvicted(key string, value Value, reason lru.EvictionReason) {
+	switch reason {
+	case lru.EvictedCapacity:
+		c.admit(key, value)
+	case lru.EvictedReplaced:
+		// 只是值被覆盖，记录仍在缓存中
+	default:
+		c.evicted(key, value)
+	}
+}
+
+// admit 决定候选记录能否进入主缓存
+// 主缓存还有空间时直接写入；否则与主缓存最久未使用的记录比较访问频率，严格更高才会写入
+func (c *Cache) admit(key string, value Value) {
+	size := int64(len(key)) + int64(value.Len())
+	if max := c.main.MaxBytes(); max != 0 && c.main.Bytes()+size > max {
+		victim, _, ok := c.main.Oldest()
+		if ok && c.sketch.Estimate(key) <= c.sketch.Estimate(victim) {
+			c.evicted(key, value)
+			return
+		}
+	}
+	if err := c.main.TryAdd(key, value); err != nil {
+		c.evicted(key, value)
+	}
+}
+
+// Get 查找键对应的值，无论是否命中都会记录一次访问
+func (c *Cache) Get(key string) (value Value, ok bool) {
+	c.sketch.Increment(key)
+	if value, ok = c.window.Get(key); ok {
+		return
+	}
+	return c.main.Get(key)
+}
+
+// Add 新增或更新一条记录，新记录先进入窗口缓存，比窗口还大的记录直接进行准入检查
+func (c *Cache) Add(key string, value Value) {
+	if c.main.Contains(key) {
+		c.main.Add(key, value)
+		return
+	}
+	if max := c.window.MaxBytes(); max != 0 && int64(len(key))+int64(value.Len()) > max {
+		c.window.Remove(key)
+		c.admit(key, value)
+		return
+	}
+	c.window.Add(key, value)
+}
+
+// Remove 删除键对应的记录，返回记录是否存在
+func (c *Cache) Remove(key string) bool {
+	return c.window.Remove(key) || c.main.Remove(key)
+}
+
+// RemoveOldest 移除主缓存中最久未使用的记录，主缓存为空时移除窗口中最久未使用的记录
+func (c *Cache) RemoveOldest() {
+	if c.main.Len() > 0 {
+		c.main.RemoveOldest()
+		return
+	}
+	// 直接删除，不再进行准入检查
+	if key, _, ok := c.window.Oldest(); ok {
+		c.window.Remove(key)
+	}
+}
+
+// Len 返回缓存中条目的数量
+func (c *Cache) Len() int {
+	return c.window.Len() + c.main.Len()
+}
+
+// Bytes 返回缓存当前已使用的内存
+func (c *Cache) Bytes() int64 {
+	return c.window.Bytes() + c.main.Bytes()
+}
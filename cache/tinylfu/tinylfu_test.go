@@ -0,0 +1,123 @@
+package tinylfu
+
+import (
+	"cache/lru"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+type String string
+
+func (d String) Len() int {
+	return len(d)
+}
+
+func TestSketch(t *testing.T) {
+	s := newSketch(1024)
+	for i := 0; i < 10; i++ {
+		s.Increment("hot")
+	}
+	s.Increment("cold")
+	if n := s.Estimate("hot"); n < 10 {
+		t.Fatalf("expect hot estimated at least 10 times, got %d", n)
+	}
+	if n := s.Estimate("cold"); n != 1 {
+		t.Fatalf("expect cold estimated once, got %d", n)
+	}
+	if n := s.Estimate("missing"); n != 0 {
+		t.Fatalf("expect missing estimated 0 times, got %d", n)
+	}
+	// 衰减后计数减半，门卫被清空
+	s.reset()
+	if n := s.Estimate("hot"); n < 4 || n > 5 {
+		t.Fatalf("expect hot estimated about 4 times after reset, got %d", n)
+	}
+	if n := s.Estimate("cold"); n != 0 {
+		t.Fatalf("expect cold forgotten after reset, got %d", n)
+	}
+}
+
+// TestAdmission 频繁访问的键不会被大量只访问一次的键挤出缓存
+func TestAdmission(t *testing.T) {
+	evicted := 0
+	c := New(int64(1000), 1000, func(key string, value Value) {
+		evicted++
+	})
+	hot := make([]string, 10)
+	for i := range hot {
+		hot[i] = "hot" + strconv.Itoa(i)
+	}
+	for round := 0; round < 5; round++ {
+		for _, k := range hot {
+			if _, ok := c.Get(k); !ok {
+				c.Add(k, String("0123456789"))
+			}
+		}
+	}
+	for i := 0; i < 1000; i++ {
+		k := "scan" + strconv.Itoa(i)
+		if _, ok := c.Get(k); !ok {
+			c.Add(k, String("0123456789"))
+		}
+	}
+	for _, k := range hot {
+		if _, ok := c.Get(k); !ok {
+			t.Fatalf("hot key %s should not be evicted by one-hit wonders", k)
+		}
+	}
+	if c.Bytes() > 1000 || evicted == 0 {
+		t.Fatalf("cache should stay within 1000 bytes, got %d bytes, %d evicted", c.Bytes(), evicted)
+	}
+}
+
+// trace 生成访问序列：热点键服从 Zipf 分布，中间穿插只访问一次的顺序扫描
+func trace(n int) []string {
+	r := rand.New(rand.NewSource(1))
+	zipf := rand.NewZipf(r, 1.2, 1, 100000)
+	keys := make([]string, 0, n)
+	scan := 0
+	for len(keys) < n {
+		if r.Intn(1000) < 5 {
+			for i := 0; i < 50 && len(keys) < n; i++ {
+				keys = append(keys, "scan"+strconv.Itoa(scan))
+				scan++
+			}
+			continue
+		}
+		keys = append(keys, "key"+strconv.FormatUint(zipf.Uint64(), 10))
+	}
+	return keys
+}
+
+type policy interface {
+	Get(key string) (Value, bool)
+	Add(key string, value Value)
+}
+
+// benchmarkHitRatio 按 cache-aside 的方式回放访问序列，并报告命中率
+func benchmarkHitRatio(b *testing.B, newCache func() policy) {
+	keys := trace(200000)
+	var hits, total int
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c := newCache()
+		for _, k := range keys {
+			total++
+			if _, ok := c.Get(k); ok {
+				hits++
+				continue
+			}
+			c.Add(k, String("0123456789"))
+		}
+	}
+	b.ReportMetric(float64(hits)*100/float64(total), "hit%")
+}
+
+func BenchmarkHitRatioLRU(b *testing.B) {
+	benchmarkHitRatio(b, func() policy { return lru.New(int64(20000), nil) })
+}
+
+func BenchmarkHitRatioTinyLFU(b *testing.B) {
+	benchmarkHitRatio(b, func() policy { return New(int64(20000), 10000, nil) })
+}
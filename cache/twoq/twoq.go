@@ -0,0 +1,157 @@
+package twoq
+
+import (
+	"cache/lru"
+	"container/list"
+)
+
+// recentRatio 是 A1in 队列最多占用的内存比例，取自 2Q 论文的推荐值
+const recentRatio = 4
+
+// Cache 是 2Q 淘汰策略的缓存，能够抵抗一次性的顺序扫描冲刷热点数据
+// 首次写入的记录进入 FIFO 队列 A1in；被淘汰的 A1in 记录只在 A1out 中保留键；
+// 再次写入 A1out 中的键时，说明它被反复使用，直接进入按 LRU 管理的 Am 队列
+type Cache struct {
+	maxBytes    int64                         // 允许使用的最大内存，0 表示不限制
+	nbytes      int64                         // 当前已使用的内存
+	recentBytes int64                         // A1in 队列已使用的内存
+	recent      *list.List                    // A1in，先进先出，保存只被写入过一次的记录
+	frequent    *list.List                    // Am，最近最少使用，保存被反复使用的记录
+	ghosts      *list.List                    // A1out，只保存从 A1in 淘汰的键，不占用 maxBytes
+	cache       map[string]*list.Element      // 键是字符串，值是 A1in 或 Am 中对应节点的指针
+	ghostIndex  map[string]*list.Element      // 键是字符串，值是 A1out 中对应节点的指针
+	OnEvicted   func(key string, value Value) // 某条记录被移除时的回调函数，可以为 nil
+}
+
+type entry struct {
+	key      string
+	value    Value
+	frequent bool // 是否位于 Am 队列
+}
+
+// Value 与 lru.Value 相同，使不同淘汰策略的缓存可以互相替换
+type Value = lru.Value
+
+// New 是Cache的构造方法，用来实例化Cache
+func New(maxBytes int64, onEvicted func(string, Value)) *Cache {
+	return &Cache{
+		maxBytes:   maxBytes,
+		recent:     list.New(),
+		frequent:   list.New(),
+		ghosts:     list.New(),
+		cache:      make(map[string]*list.Element),
+		ghostIndex: make(map[string]*list.Element),
+		OnEvicted:  onEvicted,
+	}
+}
+
+// Get 查找键对应的值，只有 Am 中的记录会被移动到队首，A1in 中的记录保持先进先出
+func (c *Cache) Get(key string) (value Value, ok bool) {
+	if ele, ok := c.cache[key]; ok {
+		kv := ele.Value.(*entry)
+		if kv.frequent {
+			c.frequent.MoveToFront(ele)
+		}
+		return kv.value, true
+	}
+	return
+}
+
+// RemoveOldest 按 2Q 的规则淘汰一条记录：A1in 超出配额时淘汰 A1in 最早的记录，否则淘汰 Am 最久未使用的记录
+func (c *Cache) RemoveOldest() {
+	if c.recent.Len() > 0 && (c.frequent.Len() == 0 || c.recentBytes > c.maxBytes/recentRatio) {
+		ele := c.recent.Back()
+		c.removeElement(ele)
+		c.addGhost(ele.Value.(*entry).key)
+		return
+	}
+	if ele := c.frequent.Back(); ele != nil {
+		c.removeElement(ele)
+	}
+}
+
+// Remove 删除键对应的记录，返回记录是否存在，A1out 中的键也会一并遗忘
+func (c *Cache) Remove(key string) bool {
+	c.removeGhost(key)
+	if ele, ok := c.cache[key]; ok {
+		c.removeElement(ele)
+		return true
+	}
+	return false
+}
+
+func (c *Cache) removeElement(ele *list.Element) {
+	kv := ele.Value.(*entry)
+	size := int64(len(kv.key)) + int64(kv.value.Len())
+	if kv.frequent {
+		c.frequent.Remove(ele)
+	} else {
+		c.recent.Remove(ele)
+		c.recentBytes -= size
+	}
+	delete(c.cache, kv.key)
+	c.nbytes -= size
+	if c.OnEvicted != nil {
+		c.OnEvicted(kv.key, kv.value)
+	}
+}
+
+// addGhost 将键加入 A1out，A1out 最多保留常驻条目数一半的键
+func (c *Cache) addGhost(key string) {
+	c.ghostIndex[key] = c.ghosts.PushFront(key)
+	limit := len(c.cache) / 2
+	if limit < 1 {
+		limit = 1
+	}
+	for c.ghosts.Len() > limit {
+		c.removeGhost(c.ghosts.Back().Value.(string))
+	}
+}
+
+// removeGhost 从 A1out 中删除键，返回键是否存在
+func (c *Cache) removeGhost(key string) bool {
+	if ele, ok := c.ghostIndex[key]; ok {
+		c.ghosts.Remove(ele)
+		delete(c.ghostIndex, key)
+		return true
+	}
+	return false
+}
+
+// Add 新增或更新一条记录
+func (c *Cache) Add(key string, value Value) {
+	if ele, ok := c.cache[key]; ok {
+		kv := ele.Value.(*entry)
+		delta := int64(value.Len()) - int64(kv.value.Len())
+		c.nbytes += delta
+		if kv.frequent {
+			c.frequent.MoveToFront(ele)
+		} else {
+			c.recentBytes += delta
+		}
+		kv.value = value
+	} else {
+		size := int64(len(key)) + int64(value.Len())
+		if c.removeGhost(key) {
+			// 最近刚从 A1in 淘汰又被写回，说明是热点数据，直接进入 Am
+			c.cache[key] = c.frequent.PushFront(&entry{key: key, value: value, frequent: true})
+		} else {
+			c.cache[key] = c.recent.PushFront(&entry{key: key, value: value})
+			c.recentBytes += size
+		}
+		c.nbytes += size
+	}
+	for c.maxBytes != 0 && c.maxBytes < c.nbytes {
+		c.RemoveOldest()
+	}
+}
+
+// Len 返回缓存中条目的数量，不包括 A1out 中只保存了键的记录
+func (c *Cache) Len() int {
+	return len(c.cache)
+}
+
+// Bytes 返回缓存当前已使用的内存
+func (c *Cache) Bytes() int64 {
+	return c.nbytes
+}
@@ -0,0 +1,39 @@
+package twoq
+
+import (
+	"strconv"
+	"testing"
+)
+
+type String string
+
+func (d String) Len() int {
+	return len(d)
+}
+
+// TestScanResistance 热点键经过 A1out 进入 Am 后，一次性的顺序扫描不会把它们淘汰
+func TestScanResistance(t *testing.T) {
+	c := New(int64(100), nil)
+	hot := []string{"h1", "h2", "h3"}
+	for _, k := range hot {
+		c.Add(k, String("v"))
+	}
+	// 用一批冷数据把热点键挤出 A1in，再写回使其进入 Am
+	for i := 0; i < 16; i++ {
+		c.Add("cold"+strconv.Itoa(i), String("v"))
+	}
+	for _, k := range hot {
+		if _, ok := c.Get(k); !ok {
+			c.Add(k, String("v"))
+		}
+	}
+	// 顺序扫描
+	for i := 0; i < 1000; i++ {
+		c.Add("scan"+strconv.Itoa(i), String("v"))
+	}
+	for _, k := range hot {
+		if _, ok := c.Get(k); !ok {
+			t.Fatalf("hot key %s should survive the scan", k)
+		}
+	}
+}
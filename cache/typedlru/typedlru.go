@@ -0,0 +1,89 @@
+package typedlru
+
+import "container/list"
+
+// Cache 是使用泛型的 LRU 缓存，键和值的类型在编译期确定，不需要类型断言
+// 语义与 lru.Cache 相同：Get 会将记录标记为最近使用，超出容量时从最久未使用的记录开始淘汰
+type Cache[K comparable, V any] struct {
+	maxSize   int64                      // 允许的最大容量，0 表示不限制
+	size      int64                      // 当前已使用的容量
+	sizeOf    func(key K, value V) int64 // 计算一条记录占用的容量
+	ll        *list.List                 // 双向链表
+	cache     map[K]*list.Element        // 键是 K，值是双向链表中对应节点的指针
+	OnEvicted func(key K, value V)       // 某条记录被移除时的回调函数，可以为 nil
+}
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// New 创建按条目数限制容量的缓存，maxEntries 为 0 表示不限制
+func New[K comparable, V any](maxEntries int, onEvicted func(K, V)) *Cache[K, V] {
+	return NewWithSize[K, V](int64(maxEntries), nil, onEvicted)
+}
+
+// NewWithSize 创建使用 sizeOf 计算记录大小的缓存，maxSize 为 0 表示不限制
+// 例如 sizeOf 返回 len(key)+len(value) 时，行为与 lru.Cache 按字节限制内存相同；sizeOf 为 nil 时每条记录计为 1，即按条目数限制
+func NewWithSize[K comparable, V any](maxSize int64, sizeOf func(key K, value V) int64, onEvicted func(K, V)) *Cache[K, V] {
+	if sizeOf == nil {
+		sizeOf = func(K, V) int64 { return 1 }
+	}
+	return &Cache[K, V]{
+		maxSize:   maxSize,
+		sizeOf:    sizeOf,
+		ll:        list.New(),
+		cache:     make(map[K]*list.Element),
+		OnEvicted: onEvicted,
+	}
+}
+
+// Get 查找键对应的值，并将其标记为最近使用
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	if ele, ok := c.cache[key]; ok {
+		c.ll.MoveToFront(ele)
+		return ele.Value.(*entry[K, V]).value, true
+	}
+	return
+}
+
+// RemoveOldest 移除最近最少使用的记录
+func (c *Cache[K, V]) RemoveOldest() {
+	ele := c.ll.Back()
+	if ele != nil {
+		c.ll.Remove(ele)
+		kv := ele.Value.(*entry[K, V])
+		delete(c.cache, kv.key)
+		c.size -= c.sizeOf(kv.key, kv.value)
+		if c.OnEvicted != nil {
+			c.OnEvicted(kv.key, kv.value)
+		}
+	}
+}
+
+// Add 新增或更新一条记录，超出容量时淘汰最近最少使用的记录
+func (c *Cache[K, V]) Add(key K, value V) {
+	if ele, ok := c.cache[key]; ok {
+		c.ll.MoveToFront(ele)
+		kv := ele.Value.(*entry[K, V])
+		c.size += c.sizeOf(key, value) - c.sizeOf(key, kv.value)
+		kv.value = value
+	} else {
+		ele := c.ll.PushFront(&entry[K, V]{key: key, value: value})
+		c.cache[key] = ele
+		c.size += c.sizeOf(key, value)
+	}
+	for c.maxSize != 0 && c.maxSize < c.size {
+		c.RemoveOldest()
+	}
+}
+
+// Len 返回缓存中条目的数量
+func (c *Cache[K, V]) Len() int {
+	return c.ll.Len()
+}
+
+// Size 返回当前已使用的容量
+func (c *Cache[K, V]) Size() int64 {
+	return c.size
+}
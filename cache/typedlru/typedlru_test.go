@@ -0,0 +1,66 @@
+package typedlru
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	lru := New[string, int](0, nil)
+	lru.Add("key1", 1234)
+	if v, ok := lru.Get("key1"); !ok || v != 1234 {
+		t.Fatalf("cache hit key1=1234 failed")
+	}
+	if _, ok := lru.Get("key2"); ok {
+		t.Fatalf("cache miss key2 failed")
+	}
+}
+
+func TestRemoveoldest(t *testing.T) {
+	lru := New[int, string](2, nil)
+	lru.Add(1, "v1")
+	lru.Add(2, "v2")
+	lru.Get(1)
+	lru.Add(3, "v3")
+
+	if _, ok := lru.Get(2); ok || lru.Len() != 2 {
+		t.Fatalf("Removeoldest 2 failed")
+	}
+}
+
+// TestNilSizeOf sizeOf 为 nil 时按条目数限制容量
+func TestNilSizeOf(t *testing.T) {
+	lru := NewWithSize[string, string](2, nil, nil)
+	lru.Add("k1", "v1")
+	lru.Add("k2", "v2")
+	lru.Add("k3", "v3")
+	if _, ok := lru.Get("k1"); ok || lru.Len() != 2 {
+		t.Fatalf("expect k1 evicted by entry count, len=%d", lru.Len())
+	}
+}
+
+func TestOnEvicted(t *testing.T) {
+	keys := make([]string, 0)
+	lru := NewWithSize(int64(10), func(key string, value []byte) int64 {
+		return int64(len(key) + len(value))
+	}, func(key string, value []byte) {
+		keys = append(keys, key)
+	})
+	lru.Add("key1", []byte("123456"))
+	lru.Add("k2", []byte("k2"))
+	lru.Add("k3", []byte("k3"))
+	lru.Add("k4", []byte("k4"))
+
+	expect := []string{"key1", "k2"}
+	if !reflect.DeepEqual(expect, keys) {
+		t.Fatalf("Call OnEvicted failed, expect keys equals to %s", expect)
+	}
+	if lru.Size() != 8 {
+		t.Fatalf("expect size 8, got %d", lru.Size())
+	}
+	// 覆盖已有的键时按新旧值的差值调整容量
+	lru.Add("k3", []byte("k3k3"))
+	if lru.Size() != 10 || lru.Len() != 2 {
+		t.Fatalf("overwrite k3 failed, size=%d len=%d", lru.Size(), lru.Len())
+	}
+}
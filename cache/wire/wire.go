@@ -0,0 +1,206 @@
+package wire
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ContentType 是使用该格式编码的 HTTP 请求和响应的 Content-Type
+const ContentType = "application/x-cache-wire"
+
+// Version 是当前编码使用的版本号
+// 新版本只能在消息末尾追加字段，旧版本的解码器会忽略无法识别的尾部数据
+// 版本 2 在请求末尾追加了 Op 字段
+const Version = 2
+
+// magic 是每条消息开头的魔数，用于快速识别非本格式的数据
+var magic = [2]byte{'C', 'W'}
+
+var (
+	ErrBadMagic   = errors.New("wire: bad magic")
+	ErrBadVersion = errors.New("wire: unsupported version")
+	ErrTruncated  = errors.New("wire: message truncated")
+)
+
+// Code 是响应的状态码
+type Code uint8
+
+const (
+	CodeOK         Code = iota // 成功，Value 中是键对应的值
+	CodeBadRequest             // 请求格式错误
+	CodeNotFound               // 节点上没有对应的 group
+	CodeError                  // 加载数据时出错，Error 中是错误信息
+)
+
+func (c Code) String() string {
+	switch c {
+	case CodeOK:
+		return "ok"
+	case CodeBadRequest:
+		return "bad request"
+	case CodeNotFound:
+		return "not found"
+	case CodeError:
+		return "error"
+	}
+	return fmt.Sprintf("code(%d)", uint8(c))
+}
+
+// Op 是请求的操作类型
+type Op uint8
+
+const (
+	OpGet    Op = iota // 获取键对应的值
+	OpDelete           // 删除节点本地缓存中的键，版本 2 起支持
+)
+
+// Request 是节点间的请求
+type Request struct {
+	Version uint8
+	Group   string
+	Key     string
+	Op      Op // 版本 1 的请求没有该字段，视为 OpGet
+}
+
+// Response 是节点间获取数据的响应
+type Response struct {
+	Version uint8
+	Code    Code
+	Value   []byte
+	TTL     time.Duration // 保留字段：值的剩余有效期，按毫秒精度传输；目前 Group 中的记录没有过期时间，服务端总是填 0，客户端也不读取
+	Error   string
+}
+
+// 编码格式：
+//
+//	Request:  magic(2) | version(1) | len(group) group | len(key) key | op(1)
+//	Response: magic(2) | version(1) | code(1) | ttl 毫秒 | len(value) value | len(error) error
+//
+// 其中长度和 ttl 都使用 uvarint 编码，ttl 是为记录过期时间预留的字段，目前总是 0
+
+// Marshal 编码请求，Version 为 0 时使用当前版本
+func (r *Request) Marshal() []byte {
+	b := appendHeader(nil, r.Version)
+	b = appendBytes(b, []byte(r.Group))
+	b = appendBytes(b, []byte(r.Key))
+	if r.Version == 0 || r.Version >= 2 {
+		b = append(b, byte(r.Op))
+	}
+	return b
+}
+
+// Unmarshal 解码请求
+func (r *Request) Unmarshal(data []byte) error {
+	d := decoder{data: data}
+	r.Version = d.header()
+	r.Group = string(d.bytes())
+	r.Key = string(d.bytes())
+	r.Op = OpGet
+	if d.err == nil && len(d.data) > 0 {
+		r.Op = Op(d.byte())
+	}
+	return d.err
+}
+
+// Marshal 编码响应，Version 为 0 时使用当前版本
+func (r *Response) Marshal() []byte {
+	b := appendHeader(nil, r.Version)
+	b = append(b, byte(r.Code))
+	b = binary.AppendUvarint(b, uint64(r.TTL/time.Millisecond))
+	b = appendBytes(b, r.Value)
+	b = appendBytes(b, []byte(r.Error))
+	return b
+}
+
+// Unmarshal 解码响应
+func (r *Response) Unmarshal(data []byte) error {
+	d := decoder{data: data}
+	r.Version = d.header()
+	r.Code = Code(d.byte())
+	r.TTL = time.Duration(d.uvarint()) * time.Millisecond
+	r.Value = d.bytes()
+	r.Error = string(d.bytes())
+	return d.err
+}
+
+func appendHeader(b []byte, version uint8) []byte {
+	if version == 0 {
+		version = Version
+	}
+	return append(append(b, magic[:]...), version)
+}
+
+func appendBytes(b []byte, p []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(len(p)))
+	return append(b, p...)
+}
+
+// decoder 顺序读取消息中的字段，出错后的读取都返回零值，只需在最后检查一次 err
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) header() uint8 {
+	if d.err != nil {
+		return 0
+	}
+	if len(d.data) < len(magic)+1 {
+		d.err = ErrTruncated
+		return 0
+	}
+	if d.data[0] != magic[0] || d.data[1] != magic[1] {
+		d.err = ErrBadMagic
+		return 0
+	}
+	version := d.data[2]
+	if version == 0 {
+		d.err = ErrBadVersion
+		return 0
+	}
+	d.data = d.data[3:]
+	return version
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil {
+		return 0
+	}
+	if len(d.data) < 1 {
+		d.err = ErrTruncated
+		return 0
+	}
+	b := d.data[0]
+	d.data = d.data[1:]
+	return b
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.err = ErrTruncated
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.uvarint()
+	if d.err != nil {
+		return nil
+	}
+	if uint64(len(d.data)) < n {
+		d.err = ErrTruncated
+		return nil
+	}
+	b := make([]byte, n)
+	copy(b, d.data)
+	d.data = d.data[n:]
+	return b
+}
@@ -0,0 +1,77 @@
+package wire
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRequest(t *testing.T) {
+	req := Request{Group: "scores", Key: "Tom", Op: OpDelete}
+	var got Request
+	if err := got.Unmarshal(req.Marshal()); err != nil {
+		t.Fatal(err)
+	}
+	expect := Request{Version: Version, Group: "scores", Key: "Tom", Op: OpDelete}
+	if !reflect.DeepEqual(expect, got) {
+		t.Fatalf("expect %+v, got %+v", expect, got)
+	}
+}
+
+func TestResponse(t *testing.T) {
+	res := Response{Code: CodeError, Value: []byte("630"), TTL: 1500 * time.Millisecond, Error: "boom"}
+	var got Response
+	if err := got.Unmarshal(res.Marshal()); err != nil {
+		t.Fatal(err)
+	}
+	res.Version = Version
+	if !reflect.DeepEqual(res, got) {
+		t.Fatalf("expect %+v, got %+v", res, got)
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	data := (&Response{Value: []byte("0123456789")}).Marshal()
+	for i := 0; i < len(data); i++ {
+		var res Response
+		if err := res.Unmarshal(data[:i]); err != ErrTruncated {
+			t.Fatalf("decode %d of %d bytes: expect ErrTruncated, got %v", i, len(data), err)
+		}
+	}
+}
+
+func TestBadHeader(t *testing.T) {
+	var req Request
+	if err := req.Unmarshal([]byte("630")); err != ErrBadMagic {
+		t.Fatalf("expect ErrBadMagic, got %v", err)
+	}
+	if err := req.Unmarshal([]byte{'C', 'W', 0, 0, 0}); err != ErrBadVersion {
+		t.Fatalf("expect ErrBadVersion, got %v", err)
+	}
+}
+
+// TestForwardCompatible 更新版本的消息在末尾追加了字段，当前版本仍能解码已知的字段
+func TestForwardCompatible(t *testing.T) {
+	data := (&Response{Version: Version + 1, Value: []byte("630")}).Marshal()
+	data = append(data, 0x01, 0x02, 0x03)
+	var res Response
+	if err := res.Unmarshal(data); err != nil {
+		t.Fatal(err)
+	}
+	if res.Version != Version+1 || string(res.Value) != "630" {
+		t.Fatalf("unexpected response %+v", res)
+	}
+}
+
+// TestRequestVersion1 版本 1 的请求没有 Op 字段，按 OpGet 处理
+func TestRequestVersion1(t *testing.T) {
+	data := (&Request{Version: 1, Group: "scores", Key: "Tom", Op: OpDelete}).Marshal()
+	var got Request
+	if err := got.Unmarshal(data); err != nil {
+		t.Fatal(err)
+	}
+	expect := Request{Version: 1, Group: "scores", Key: "Tom", Op: OpGet}
+	if !reflect.DeepEqual(expect, got) {
+		t.Fatalf("expect %+v, got %+v", expect, got)
+	}
+}